@@ -0,0 +1,183 @@
+package code
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// histogramBuckets is the number of bars in the inline histogram of a
+	// benchmark.
+	histogramBuckets = 12
+	// maxBenchRuns limits the number of runs to keep a typo from blocking the
+	// presentation.
+	maxBenchRuns = 10000
+)
+
+var histogramBars = []rune("▁▂▃▄▅▆▇█")
+
+// Stats represents the timings of a benchmarked code block.
+type Stats struct {
+	// Compile is the time spent running the Build commands of the language,
+	// it is zero for interpreted languages.
+	Compile time.Duration
+	// Warmup is the number of runs which were executed but not measured.
+	Warmup int
+	// Runs are the measured durations of the Commands of the language, sorted
+	// in ascending order.
+	Runs []time.Duration
+}
+
+// Benchmark compiles the code block once and runs it warmup times without
+// measuring, followed by runs measured executions. The output of the last run
+// is returned together with a summary of the timings.
+func Benchmark(code Block, runs, warmup int) Result {
+	language, repl, cleanup, res := prepare(code)
+	if cleanup == nil {
+		return res
+	}
+	defer cleanup()
+
+	stats := &Stats{Warmup: warmup}
+
+	var (
+		out      string
+		exitCode int
+	)
+	if len(language.Build) > 0 {
+		start := time.Now()
+		out, exitCode = run(language.Build, repl, code.Stdin)
+		stats.Compile = time.Since(start)
+	}
+	if exitCode != 0 {
+		return Result{
+			Out:      out,
+			ExitCode: exitCode,
+		}
+	}
+
+	for i := 0; i < warmup; i++ {
+		run(language.Commands, repl, code.Stdin)
+	}
+
+	for i := 0; i < runs; i++ {
+		start := time.Now()
+		out, exitCode = run(language.Commands, repl, code.Stdin)
+		stats.Runs = append(stats.Runs, time.Since(start))
+		if exitCode != 0 {
+			break
+		}
+	}
+	slices.Sort(stats.Runs)
+
+	return Result{
+		Out:           strings.TrimSuffix(out, "\n") + "\n\n" + stats.String(),
+		ExitCode:      exitCode,
+		ExecutionTime: stats.Median(),
+		Stats:         stats,
+	}
+}
+
+func benchAttributes(attributes map[string]string) (int, int, error) {
+	runs, err := strconv.Atoi(attributes["bench"])
+	if err != nil || runs < 1 || runs > maxBenchRuns {
+		return 0, 0, fmt.Errorf("bench must be a number between 1 and %d", maxBenchRuns)
+	}
+
+	warmup := 0
+	if w, ok := attributes["warmup"]; ok {
+		warmup, err = strconv.Atoi(w)
+		if err != nil || warmup < 0 || warmup > maxBenchRuns {
+			return 0, 0, fmt.Errorf("warmup must be a non-negative number up to %d", maxBenchRuns)
+		}
+	}
+	return runs, warmup, nil
+}
+
+// Min returns the fastest run.
+func (s *Stats) Min() time.Duration {
+	return s.Percentile(0)
+}
+
+// Median returns the median run.
+func (s *Stats) Median() time.Duration {
+	return s.Percentile(50)
+}
+
+// Max returns the slowest run.
+func (s *Stats) Max() time.Duration {
+	return s.Percentile(100)
+}
+
+// Percentile returns the p-th percentile of the runs using the nearest-rank
+// method.
+func (s *Stats) Percentile(p float64) time.Duration {
+	if len(s.Runs) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(s.Runs)))) - 1
+	return s.Runs[max(0, min(rank, len(s.Runs)-1))]
+}
+
+// Histogram renders the distribution of the runs as a single line of bars
+// between the fastest and the slowest run.
+func (s *Stats) Histogram() string {
+	if len(s.Runs) == 0 {
+		return ""
+	}
+
+	buckets := make([]int, histogramBuckets)
+	span := s.Max() - s.Min()
+	for _, r := range s.Runs {
+		i := 0
+		if span > 0 {
+			i = int(float64(r-s.Min()) / float64(span) * float64(histogramBuckets-1))
+		}
+		buckets[i]++
+	}
+
+	highest := slices.Max(buckets)
+	var b strings.Builder
+	for _, count := range buckets {
+		if count == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(histogramBars[(count*len(histogramBars)-1)/highest])
+	}
+	return b.String()
+}
+
+// String returns a summary of the benchmark.
+func (s *Stats) String() string {
+	var b strings.Builder
+	if s.Compile > 0 {
+		fmt.Fprintf(&b, "compile  %v\n", round(s.Compile))
+	}
+	fmt.Fprintf(&b, "runs     %d", len(s.Runs))
+	if s.Warmup > 0 {
+		fmt.Fprintf(&b, " (+%d warm-up)", s.Warmup)
+	}
+	fmt.Fprintf(&b, "\nmin      %v\n", round(s.Min()))
+	fmt.Fprintf(&b, "median   %v\n", round(s.Median()))
+	fmt.Fprintf(&b, "p95      %v\n", round(s.Percentile(95)))
+	fmt.Fprintf(&b, "max      %v\n", round(s.Max()))
+	fmt.Fprintf(&b, "         %s\n", s.Histogram())
+	return b.String()
+}
+
+// round drops insignificant digits of a duration for display.
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(10 * time.Nanosecond)
+	}
+}
@@ -0,0 +1,61 @@
+package code_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c0rydoras/folien/internal/code"
+)
+
+func TestStats(t *testing.T) {
+	var runs []time.Duration
+	for i := 1; i <= 20; i++ {
+		runs = append(runs, time.Duration(i)*time.Millisecond)
+	}
+	stats := code.Stats{Runs: runs}
+
+	tt := []struct {
+		name     string
+		got      time.Duration
+		expected time.Duration
+	}{
+		{"min", stats.Min(), time.Millisecond},
+		{"median", stats.Median(), 10 * time.Millisecond},
+		{"p95", stats.Percentile(95), 19 * time.Millisecond},
+		{"max", stats.Max(), 20 * time.Millisecond},
+	}
+	for _, tc := range tt {
+		if tc.got != tc.expected {
+			t.Errorf("%s = %v, want %v", tc.name, tc.got, tc.expected)
+		}
+	}
+
+	if h := stats.Histogram(); len([]rune(h)) != 12 || strings.TrimSpace(h) == "" {
+		t.Errorf("unexpected histogram %q", h)
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	block := code.Block{
+		Code:       `echo "Hello, bench!"`,
+		Language:   "bash",
+		Attributes: map[string]string{"bench": "5", "warmup": "1"},
+	}
+
+	r := code.Execute(block)
+	if r.ExitCode != 0 {
+		t.Fatalf("unexpected exit code %d: %s", r.ExitCode, r.Out)
+	}
+	if r.Stats == nil || len(r.Stats.Runs) != 5 || r.Stats.Warmup != 1 {
+		t.Fatalf("unexpected stats %+v", r.Stats)
+	}
+	if !strings.HasPrefix(r.Out, "Hello, bench!\n\n") || !strings.Contains(r.Out, "median") {
+		t.Fatalf("unexpected output %q", r.Out)
+	}
+
+	block.Attributes["bench"] = "many"
+	if r := code.Execute(block); r.ExitCode != code.ExitCodeInternalError {
+		t.Fatalf("expected internal error for invalid bench attribute, got %+v", r)
+	}
+}
@@ -9,12 +9,24 @@ import (
 	"time"
 
 	"github.com/c0rydoras/folien/pkg/parser"
+	"github.com/yuin/goldmark/ast"
 )
 
 // Block represents a code block.
 type Block struct {
 	Code     string
 	Language string
+	// Attributes are the key=value pairs following the language in the info
+	// string of the code block, e.g. name=generate.
+	Attributes map[string]string
+	// Stdin is passed as standard input to the executed program.
+	Stdin string
+}
+
+// Name returns the name of the block given by the name attribute, it is empty
+// for anonymous blocks.
+func (b Block) Name() string {
+	return b.Attributes["name"]
 }
 
 // Result represents the output for an executed code block.
@@ -22,6 +34,9 @@ type Result struct {
 	Out           string
 	ExitCode      int
 	ExecutionTime time.Duration
+	// Stats holds the timings of a benchmarked block, it is nil for blocks
+	// which were executed only once.
+	Stats *Stats
 }
 
 var (
@@ -39,10 +54,7 @@ func Parse(markdown string) ([]Block, error) {
 	var rv []Block
 
 	for _, block := range codeBlocks {
-		rv = append(rv, Block{
-			Language: string(block.Language([]byte(markdown))),
-			Code:     RemoveComments(string(block.Lines().Value([]byte(markdown)))),
-		})
+		rv = append(rv, newBlock(block, []byte(markdown)))
 	}
 
 	if len(rv) == 0 {
@@ -52,56 +64,102 @@ func Parse(markdown string) ([]Block, error) {
 	return rv, nil
 }
 
+// newBlock creates a Block from a parsed fenced code block.
+func newBlock(fenced *ast.FencedCodeBlock, source []byte) Block {
+	var info string
+	if fenced.Info != nil {
+		info = string(fenced.Info.Segment.Value(source))
+	}
+	language, attributes := parser.ParseInfo(info)
+	return Block{
+		Language:   language,
+		Code:       RemoveHiddenMarkers(language, string(fenced.Lines().Value(source))),
+		Attributes: attributes,
+	}
+}
+
 const (
 	// ExitCodeInternalError represents the exit code in which the code
 	// executing the code didn't work.
 	ExitCodeInternalError = -1
 )
 
-// Execute takes a code.Block and returns the output of the executed code
+// Execute takes a code.Block and returns the output of the executed code.
+// Blocks with a bench attribute are benchmarked, see Benchmark.
 func Execute(code Block) Result {
+	if _, ok := code.Attributes["bench"]; ok {
+		runs, warmup, err := benchAttributes(code.Attributes)
+		if err != nil {
+			return Result{
+				Out:      "Error: " + err.Error(),
+				ExitCode: ExitCodeInternalError,
+			}
+		}
+		return Benchmark(code, runs, warmup)
+	}
+
+	language, repl, cleanup, res := prepare(code)
+	if cleanup == nil {
+		return res
+	}
+	defer cleanup()
+
+	// For accuracy of program execution speed, we can't put anything after
+	// recording the start time or before recording the end time.
+	start := time.Now()
+
+	out, exitCode := run(append(append(cmds{}, language.Build...), language.Commands...), repl, code.Stdin)
+
+	end := time.Now()
+
+	return Result{
+		Out:           out,
+		ExitCode:      exitCode,
+		ExecutionTime: end.Sub(start),
+	}
+}
+
+// prepare writes the code block to a temporary file and returns the language
+// of the block, the replacer for the command placeholders and a cleanup
+// function removing the file. If the block can not be prepared, cleanup is nil
+// and the returned Result contains the error.
+func prepare(code Block) (Language, *strings.Replacer, func(), Result) {
 	// Check supported language
 	language, ok := Languages[code.Language]
 	if !ok {
-		return Result{
+		return language, nil, nil, Result{
 			Out:      "Error: unsupported language",
 			ExitCode: ExitCodeInternalError,
 		}
 	}
 
 	// Write the code block to a temporary file
-	f, err := os.CreateTemp(os.TempDir(), "folien-*."+Languages[code.Language].Extension)
+	f, err := os.CreateTemp(os.TempDir(), "folien-*."+language.Extension)
 	if err != nil {
-		return Result{
+		return language, nil, nil, Result{
 			Out:      "Error: could not create file",
 			ExitCode: ExitCodeInternalError,
 		}
 	}
 
-	defer func() {
+	cleanup := func() {
 		if err := f.Close(); err != nil {
 			_ = err // ignore error
 		}
-	}()
-	defer func() {
 		if err := os.Remove(f.Name()); err != nil {
 			_ = err // ignore error
 		}
-	}()
+	}
 
 	_, err = f.WriteString(TransformCode(code.Language, code.Code))
 	if err != nil {
-		return Result{
+		cleanup()
+		return language, nil, nil, Result{
 			Out:      "Error: could not write to file",
 			ExitCode: ExitCodeInternalError,
 		}
 	}
 
-	var (
-		output   strings.Builder
-		exitCode int
-	)
-
 	// replacer for commands
 	repl := strings.NewReplacer(
 		"<file>", f.Name(),
@@ -110,11 +168,18 @@ func Execute(code Block) Result {
 		"<path>", filepath.Dir(f.Name()),
 	)
 
-	// For accuracy of program execution speed, we can't put anything after
-	// recording the start time or before recording the end time.
-	start := time.Now()
+	return language, repl, cleanup, Result{}
+}
 
-	for _, c := range language.Commands {
+// run executes the given commands one after another and returns their
+// combined output and the exit code of the last failing command.
+func run(commands cmds, repl *strings.Replacer, stdin string) (string, int) {
+	var (
+		output   strings.Builder
+		exitCode int
+	)
+
+	for _, c := range commands {
 		var command []string
 		// replace <file>, <name> and <path> in commands
 		for _, v := range c {
@@ -122,6 +187,7 @@ func Execute(code Block) Result {
 		}
 		// execute and write output
 		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Stdin = strings.NewReader(stdin)
 
 		out, err := cmd.CombinedOutput()
 		if err != nil {
@@ -143,11 +209,5 @@ func Execute(code Block) Result {
 		}
 	}
 
-	end := time.Now()
-
-	return Result{
-		Out:           output.String(),
-		ExitCode:      exitCode,
-		ExecutionTime: end.Sub(start),
-	}
+	return output.String(), exitCode
 }
@@ -133,3 +133,27 @@ fmt.Println("Hello, world!")
 		}
 	}
 }
+
+func TestSetBlockSource(t *testing.T) {
+	markdown := "# Edit\n\n~~~go name=x\nfmt.Println(1)\n~~~\n\n~~~bash\n~~~\n"
+
+	source, ok := code.BlockSource(markdown, 0)
+	if !ok || source != "fmt.Println(1)\n" {
+		t.Fatalf("unexpected source %q", source)
+	}
+	if _, ok := code.BlockSource(markdown, 2); ok {
+		t.Fatal("expected no source for missing block")
+	}
+
+	expected := "# Edit\n\n~~~go name=x\nfmt.Println(10)\n~~~\n\n~~~bash\necho hi\n~~~\n"
+	got := code.SetBlockSource(code.SetBlockSource(markdown, 0, "fmt.Println(10)"), 1, "echo hi\n")
+	if got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+
+	// empty blocks without an info string
+	got = code.SetBlockSource("# Edit\n\n```\n```\n", 0, "ls")
+	if expected := "# Edit\n\n```\nls\n```\n"; got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
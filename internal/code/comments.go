@@ -2,13 +2,23 @@ package code
 
 import (
 	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/c0rydoras/folien/pkg/parser"
 )
 
 const comment = "///"
 
+// HiddenLineMarker is prepended to hidden lines which are revealed, so that
+// they can be recognized and dimmed after rendering. It is a zero width space
+// and therefore does not change the layout of the slide.
+const HiddenLineMarker = "\u200b"
+
 var commentRegexp = regexp.MustCompile("(?m)[\r\n]+^" + comment + ".*$")
 
+var revealCommentRegexp = regexp.MustCompile("(?m)^" + comment)
+
 // HideComments removes all comments from the given content.
 func HideComments(content string) string {
 	return commentRegexp.ReplaceAllString(content, "")
@@ -19,3 +29,73 @@ func HideComments(content string) string {
 func RemoveComments(content string) string {
 	return strings.ReplaceAll(content, comment, "")
 }
+
+// HideLines hides the hidden lines of the given markdown. Lines starting with
+// the comment prefix (///) are hidden everywhere, lines starting with the
+// HiddenLine marker of a language only inside code blocks of that language.
+// If reveal is set, hidden lines are kept without their marker and prefixed
+// with HiddenLineMarker instead.
+func HideLines(markdown string, reveal bool) string {
+	source := []byte(markdown)
+	blocks := parser.CollectCodeBlocks(source)
+	slices.Reverse(blocks)
+
+	for _, block := range blocks {
+		marker := Languages[string(block.Language(source))].HiddenLine
+		if marker == "" {
+			continue
+		}
+		lines := block.Lines()
+		for i := lines.Len() - 1; i >= 0; i-- {
+			segment := lines.At(i)
+			line := string(segment.Value(source))
+			replacement, hidden := unhideLine(line, marker)
+			if !hidden {
+				continue
+			}
+			if reveal {
+				replacement = HiddenLineMarker + replacement
+			} else {
+				replacement = ""
+			}
+			source = slices.Concat(source[:segment.Start], []byte(replacement), source[segment.Stop:])
+		}
+	}
+
+	if reveal {
+		return revealCommentRegexp.ReplaceAllString(string(source), HiddenLineMarker)
+	}
+	return HideComments(string(source))
+}
+
+// RemoveHiddenMarkers strips the hidden line markers of the given language
+// and the comment prefix (///) from the code, so that it can be executed.
+func RemoveHiddenMarkers(language, code string) string {
+	if marker := Languages[language].HiddenLine; marker != "" {
+		lines := strings.SplitAfter(code, "\n")
+		for i, line := range lines {
+			lines[i], _ = unhideLine(line, marker)
+		}
+		code = strings.Join(lines, "")
+	}
+	return RemoveComments(code)
+}
+
+// unhideLine returns the line without the given hidden line marker and
+// whether the line was hidden. The marker may be indented, the indentation
+// is kept.
+func unhideLine(line, marker string) (string, bool) {
+	content := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(content)]
+
+	if strings.TrimSpace(content) == strings.TrimSpace(marker) {
+		if strings.HasSuffix(line, "\n") {
+			return indent + "\n", true
+		}
+		return indent, true
+	}
+	if rest, ok := strings.CutPrefix(content, marker); ok {
+		return indent + rest, true
+	}
+	return line, false
+}
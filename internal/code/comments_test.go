@@ -62,3 +62,30 @@ func main() {
 		t.Errorf("Expected %s, got %s", expected, RemoveComments(content))
 	}
 }
+
+func TestHideLines(t *testing.T) {
+	content := "# Rust\n\n~~~rust\n# fn main() {\n    # let hidden = 1;\n    println!(\"{}\", hidden);\n#\n# }\n~~~\n\n~~~go\n# not hidden\n///hidden\n~~~\n"
+
+	expected := "# Rust\n\n~~~rust\n    println!(\"{}\", hidden);\n~~~\n\n~~~go\n# not hidden\n~~~\n"
+	if got := HideLines(content, false); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	m := HiddenLineMarker
+	expected = "# Rust\n\n~~~rust\n" + m + "fn main() {\n" + m + "    let hidden = 1;\n    println!(\"{}\", hidden);\n" + m + "\n" + m + "}\n~~~\n\n~~~go\n# not hidden\n" + m + "hidden\n~~~\n"
+	if got := HideLines(content, true); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestRemoveHiddenMarkers(t *testing.T) {
+	content := "#!hide import os\n  #!hide x = 1\nprint(os.name)\n///print(x)\n"
+	expected := "import os\n  x = 1\nprint(os.name)\nprint(x)\n"
+
+	if got := RemoveHiddenMarkers("python", content); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	if got := RemoveHiddenMarkers("bash", "#!hide echo\n"); got != "#!hide echo\n" {
+		t.Errorf("Expected markers of other languages to be kept, got %q", got)
+	}
+}
@@ -0,0 +1,163 @@
+package code
+
+import (
+	"math/rand/v2"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// DemoLanguage is the language of code blocks containing scripted
+	// terminal demos.
+	DemoLanguage = "demo"
+	// DemoPrompt is displayed in front of every command of a demo.
+	DemoPrompt = "$ "
+	// demoCursor is displayed at the end of a command while it is typed.
+	demoCursor = "▌"
+	// demoTypingDelay is the minimum delay between two typed characters,
+	// the actual delay is randomized to look like a human typing.
+	demoTypingDelay = 35 * time.Millisecond
+)
+
+// DemoStep is a command of a demo together with its output.
+type DemoStep struct {
+	Command string
+	Out     string
+}
+
+// Demo is a scripted terminal demo, every line of a demo block is a command
+// which is typed into a simulated prompt and executed one after another.
+type Demo struct {
+	Commands []string
+	// Steps are the commands which have been typed so far.
+	Steps []DemoStep
+	// typed is the number of typed runes of the last step
+	typed   int
+	running bool
+}
+
+// NewDemo creates a Demo from the commands of the given block. Empty lines
+// are skipped and leading prompts ($) are removed.
+func NewDemo(block Block) *Demo {
+	d := &Demo{}
+	for _, line := range strings.Split(TransformCode(Bash, block.Code), "\n") {
+		if strings.TrimSpace(line) != "" {
+			d.Commands = append(d.Commands, strings.TrimSpace(line))
+		}
+	}
+	return d
+}
+
+// Busy returns whether a command is currently typed or executed.
+func (d *Demo) Busy() bool {
+	if d.running {
+		return true
+	}
+	return len(d.Steps) > 0 && d.typed < len([]rune(d.current().Command))
+}
+
+// Typing returns whether the current command is being typed.
+func (d *Demo) Typing() bool {
+	return d.Busy() && !d.running
+}
+
+// Done returns whether all commands have been executed.
+func (d *Demo) Done() bool {
+	return len(d.Steps) == len(d.Commands) && !d.Busy()
+}
+
+// Next starts typing the next command, after the last command the demo is
+// restarted. It returns false if the demo is busy or has no commands.
+func (d *Demo) Next() bool {
+	if d.Busy() || len(d.Commands) == 0 {
+		return false
+	}
+	if d.Done() {
+		d.Steps = nil
+	}
+	d.Steps = append(d.Steps, DemoStep{Command: d.Commands[len(d.Steps)]})
+	d.typed = 0
+	return true
+}
+
+// Type types the next character of the current command and returns true once
+// the command is typed completely and should be executed.
+func (d *Demo) Type() bool {
+	if !d.Typing() {
+		return false
+	}
+	d.typed++
+	if d.typed < len([]rune(d.current().Command)) {
+		return false
+	}
+	d.running = true
+	return true
+}
+
+// Skip types the rest of the current command immediately and returns true if
+// it should be executed.
+func (d *Demo) Skip() bool {
+	if !d.Typing() {
+		return false
+	}
+	d.typed = len([]rune(d.current().Command)) - 1
+	return d.Type()
+}
+
+// Finish records the output of the current command.
+func (d *Demo) Finish(out string) {
+	if !d.running {
+		return
+	}
+	d.running = false
+	d.Steps[len(d.Steps)-1].Out = out
+}
+
+// Command returns the command which is currently executed.
+func (d *Demo) Command() string {
+	if !d.running {
+		return ""
+	}
+	return d.current().Command
+}
+
+// Transcript renders the prompt, the typed commands and their output.
+func (d *Demo) Transcript() string {
+	var b strings.Builder
+	for i, step := range d.Steps {
+		command := step.Command
+		if i == len(d.Steps)-1 && d.typed < len([]rune(command)) {
+			command = string([]rune(command)[:d.typed]) + demoCursor
+		}
+		b.WriteString(DemoPrompt + command + "\n")
+		if step.Out != "" {
+			b.WriteString(strings.TrimSuffix(step.Out, "\n") + "\n")
+		}
+	}
+	if !d.Busy() {
+		b.WriteString(DemoPrompt + demoCursor + "\n")
+	}
+	return b.String()
+}
+
+func (d *Demo) current() DemoStep {
+	return d.Steps[len(d.Steps)-1]
+}
+
+// TypingDelay returns a randomized delay before the next character is typed.
+func TypingDelay() time.Duration {
+	return demoTypingDelay + rand.N(2*demoTypingDelay)
+}
+
+// RunShell executes the command with sh inside the given directory and returns
+// its combined output.
+func RunShell(command, dir string) string {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil && len(out) == 0 {
+		return err.Error()
+	}
+	return string(out)
+}
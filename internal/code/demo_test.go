@@ -0,0 +1,52 @@
+package code_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/c0rydoras/folien/internal/code"
+)
+
+func TestDemo(t *testing.T) {
+	d := code.NewDemo(code.Block{Code: "$ echo hi\n\nls\n", Language: code.DemoLanguage})
+	if len(d.Commands) != 2 || d.Commands[0] != "echo hi" || d.Commands[1] != "ls" {
+		t.Fatalf("unexpected commands %q", d.Commands)
+	}
+
+	if got := d.Transcript(); got != "$ ▌\n" {
+		t.Fatalf("unexpected initial transcript %q", got)
+	}
+
+	if !d.Next() {
+		t.Fatal("expected demo to start the first command")
+	}
+	d.Type()
+	d.Type()
+	if got := d.Transcript(); got != "$ ec▌\n" {
+		t.Fatalf("unexpected transcript while typing %q", got)
+	}
+	if d.Next() {
+		t.Fatal("expected demo to be busy while typing")
+	}
+
+	if !d.Skip() || d.Command() != "echo hi" {
+		t.Fatal("expected skip to complete the command")
+	}
+	d.Finish(code.RunShell(d.Command(), "."))
+	if got := d.Transcript(); got != "$ echo hi\nhi\n$ ▌\n" {
+		t.Fatalf("unexpected transcript after first command %q", got)
+	}
+
+	d.Next()
+	for !d.Type() {
+	}
+	d.Finish("file\n")
+	if !d.Done() {
+		t.Fatal("expected demo to be done")
+	}
+
+	d.Next()
+	if !strings.HasPrefix(d.Transcript(), "$ ▌") || len(d.Steps) != 1 {
+		t.Fatalf("expected demo to restart, got %q", d.Transcript())
+	}
+}
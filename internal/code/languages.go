@@ -13,9 +13,17 @@ type cmds [][]string
 type Language struct {
 	// Extension represents the file extension used by this language.
 	Extension string
+	// Build are the commands compiling the program before it is run, they
+	// are kept separate from Commands so that benchmarks can exclude them.
+	// Same placeholders as Commands.
+	Build cmds
 	// Commands  [][]string // placeholders: <name> file name (without
 	// extension), <file> file name, <path> path without file name
 	Commands cmds
+	// HiddenLine is the prefix of lines inside code blocks of this language
+	// which are executed but not displayed, in addition to the comment
+	// prefix (///) which works for all languages.
+	HiddenLine string
 }
 
 // Supported Languages
@@ -50,6 +58,19 @@ var shells = map[string]struct{}{
 
 var shellPromptRE = regexp.MustCompile(`(?m)^\$ ?`)
 
+// LanguageFromExtension returns the language of files with the given
+// extension (without the leading dot). Unknown extensions are returned as
+// they are, which matches the names of many other languages glamour can
+// highlight (e.g. "yaml" or "toml").
+func LanguageFromExtension(extension string) string {
+	for name, language := range Languages {
+		if language.Extension == extension {
+			return name
+		}
+	}
+	return extension
+}
+
 // Transform code, e.g. remove "$ " from shell commands
 func TransformCode(language, code string) string {
 	if _, ok := shells[language]; ok {
@@ -98,8 +119,9 @@ var Languages = map[string]Language{
 		Commands:  cmds{{"ocaml", "<file>"}},
 	},
 	Python: {
-		Extension: "py",
-		Commands:  cmds{{"python", "<file>"}},
+		Extension:  "py",
+		Commands:   cmds{{"python", "<file>"}},
+		HiddenLine: "#!hide ",
 	},
 	Perl: {
 		Extension: "pl",
@@ -107,12 +129,12 @@ var Languages = map[string]Language{
 	},
 	Rust: {
 		Extension: "rs",
-		Commands: cmds{
-			// compile code
-			{"rustc", "<file>", "-o", "<path>/<name>.run"},
-			// run compiled file
-			{"<path>/<name>.run"},
-		},
+		// compile code
+		Build: cmds{{"rustc", "<file>", "-o", "<path>/<name>.run"}},
+		// run compiled file
+		Commands: cmds{{"<path>/<name>.run"}},
+		// rustdoc style hidden lines
+		HiddenLine: "# ",
 	},
 	Java: {
 		Extension: "java",
@@ -124,10 +146,8 @@ var Languages = map[string]Language{
 	},
 	Cpp: {
 		Extension: "cpp",
-		Commands: cmds{
-			{"g++", "-std=c++20", "-o", "<path>/<name>.run", "<file>"},
-			{"<path>/<name>.run"},
-		},
+		Build:     cmds{{"g++", "-std=c++20", "-o", "<path>/<name>.run", "<file>"}},
+		Commands:  cmds{{"<path>/<name>.run"}},
 	},
 	Swift: {
 		Extension: "swift",
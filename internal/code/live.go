@@ -0,0 +1,26 @@
+package code
+
+import (
+	"time"
+)
+
+const (
+	// LiveLanguage is the language of code blocks whose command is executed
+	// repeatedly while the slide is visible.
+	LiveLanguage = "live"
+	// DefaultRefresh is the interval of live blocks without a refresh
+	// attribute.
+	DefaultRefresh = 5 * time.Second
+	// MinRefresh is the shortest interval of live blocks.
+	MinRefresh = time.Second
+)
+
+// RefreshInterval returns the interval given by the refresh attribute of a
+// live block.
+func RefreshInterval(block Block) time.Duration {
+	refresh, err := time.ParseDuration(block.Attributes["refresh"])
+	if err != nil {
+		return DefaultRefresh
+	}
+	return max(refresh, MinRefresh)
+}
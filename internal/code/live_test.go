@@ -0,0 +1,28 @@
+package code_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/c0rydoras/folien/internal/code"
+)
+
+func TestRefreshInterval(t *testing.T) {
+	tt := []struct {
+		refresh  string
+		expected time.Duration
+	}{
+		{"", code.DefaultRefresh},
+		{"invalid", code.DefaultRefresh},
+		{"10s", 10 * time.Second},
+		{"1m", time.Minute},
+		{"100ms", code.MinRefresh},
+	}
+
+	for _, tc := range tt {
+		block := code.Block{Language: code.LiveLanguage, Attributes: map[string]string{"refresh": tc.refresh}}
+		if got := code.RefreshInterval(block); got != tc.expected {
+			t.Errorf("RefreshInterval(%q) = %v, want %v", tc.refresh, got, tc.expected)
+		}
+	}
+}
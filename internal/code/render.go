@@ -0,0 +1,41 @@
+package code
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// RenderTimeout is the time a renderer may take to render a block.
+const RenderTimeout = 10 * time.Second
+
+// Render runs the renderer command with sh inside dir. The content of the
+// block is passed on stdin, the size of the area the output is displayed in
+// is passed in the COLUMNS and LINES environment variables. It returns the
+// output of the command.
+func Render(command, content, dir string, cols, rows int) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), RenderTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("COLUMNS=%d", cols),
+		fmt.Sprintf("LINES=%d", rows),
+	)
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,21 @@
+package code
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	out, err := Render(`echo "$COLUMNS x $LINES"; tr a-z A-Z`, "graph\n", t.TempDir(), 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "80 x 24\nGRAPH\n"; out != expected {
+		t.Errorf("Render() = %q, want %q", out, expected)
+	}
+
+	_, err = Render("echo broken >&2; exit 3", "", t.TempDir(), 80, 24)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected error containing stderr, got %v", err)
+	}
+}
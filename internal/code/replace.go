@@ -0,0 +1,85 @@
+package code
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/c0rydoras/folien/pkg/parser"
+)
+
+var backtickRunRegexp = regexp.MustCompile("`{3,}")
+
+// ReplaceBlocks replaces the code blocks of the given markdown, including
+// their fences, with the markdown returned by replace. replace is called with
+// the index of the block on the slide and the parsed block, blocks for which
+// replace returns false are kept.
+func ReplaceBlocks(markdown string, replace func(i int, block Block) (string, bool)) string {
+	source := []byte(markdown)
+	blocks := parser.CollectCodeBlocks(source)
+
+	for i := len(blocks) - 1; i >= 0; i-- {
+		fenced := blocks[i]
+		replacement, ok := replace(i, newBlock(fenced, source))
+		if !ok {
+			continue
+		}
+		start, stop := parser.BlockRange(fenced, source)
+		source = slices.Concat(source[:start], []byte(replacement), source[stop:])
+	}
+
+	return string(source)
+}
+
+// Fence wraps the given content in a fenced code block with the given info
+// string. The fence is chosen longer than any backtick fence inside the
+// content.
+func Fence(info, content string) string {
+	fence := "```"
+	for _, run := range backtickRunRegexp.FindAllString(content, -1) {
+		if len(run) >= len(fence) {
+			fence = strings.Repeat("`", len(run)+1)
+		}
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return fence + info + "\n" + content + fence + "\n"
+}
+
+// BlockSource returns the unprocessed content of the code block with the given
+// index, including hidden lines.
+func BlockSource(markdown string, index int) (string, bool) {
+	source := []byte(markdown)
+	blocks := parser.CollectCodeBlocks(source)
+	if index < 0 || index >= len(blocks) {
+		return "", false
+	}
+	return string(blocks[index].Lines().Value(source)), true
+}
+
+// SetBlockSource replaces the content of the code block with the given index,
+// the fences and the info string are kept.
+func SetBlockSource(markdown string, index int, content string) string {
+	source := []byte(markdown)
+	blocks := parser.CollectCodeBlocks(source)
+	if index < 0 || index >= len(blocks) {
+		return markdown
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	block := blocks[index]
+	var start, stop int
+	if lines := block.Lines(); lines.Len() > 0 {
+		start, stop = lines.At(0).Start, lines.At(lines.Len()-1).Stop
+	} else {
+		// insert after the opening fence
+		blockStart, _ := parser.BlockRange(block, source)
+		start = blockStart + strings.IndexByte(markdown[blockStart:], '\n') + 1
+		stop = start
+	}
+	return string(slices.Concat(source[:start], []byte(content), source[stop:]))
+}
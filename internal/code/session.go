@@ -0,0 +1,126 @@
+package code
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// referenceRegexp matches references to the results of named blocks inside
+// the code of a block, e.g. {{ .Blocks.generate.Out }}.
+var referenceRegexp = regexp.MustCompile(`\{\{\s*\.Blocks\.([\w-]+)\.(Out|ExitCode|ExecutionTime)\s*\}\}`)
+
+// Session executes code blocks of a presentation and caches the results of
+// named blocks, so that other blocks can use their output as stdin
+// (stdin=@name) or substitute it into their code ({{ .Blocks.name.Out }}).
+// Blocks are executed one after another, also when Execute is called
+// concurrently.
+type Session struct {
+	mu      sync.Mutex
+	named   map[string]Block
+	results map[string]Result
+}
+
+// NewSession creates a new session which knows about all named blocks in the
+// given folien.
+func NewSession(folien []string) *Session {
+	s := &Session{
+		named:   map[string]Block{},
+		results: map[string]Result{},
+	}
+	for _, slide := range folien {
+		blocks, err := Parse(slide)
+		if err != nil {
+			continue
+		}
+		for _, block := range blocks {
+			if name := block.Name(); name != "" {
+				s.named[name] = block
+			}
+		}
+	}
+	return s
+}
+
+// Execute runs the given block after resolving the blocks it depends on.
+// Dependencies are executed only once per session, while the given block is
+// always executed and its result cached if it is named.
+func (s *Session) Execute(block Block) Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.execute(block, map[string]bool{})
+}
+
+func (s *Session) execute(block Block, visiting map[string]bool) Result {
+	name := block.Name()
+	if name != "" {
+		visiting[name] = true
+		defer delete(visiting, name)
+	}
+
+	for _, dependency := range Dependencies(block) {
+		if _, ok := s.results[dependency]; ok {
+			continue
+		}
+		if visiting[dependency] {
+			return Result{
+				Out:      fmt.Sprintf("Error: dependency cycle through block %q", dependency),
+				ExitCode: ExitCodeInternalError,
+			}
+		}
+		named, ok := s.named[dependency]
+		if !ok {
+			return Result{
+				Out:      fmt.Sprintf("Error: unknown block %q", dependency),
+				ExitCode: ExitCodeInternalError,
+			}
+		}
+		if res := s.execute(named, visiting); res.ExitCode == ExitCodeInternalError {
+			return res
+		}
+	}
+
+	if ref, ok := strings.CutPrefix(block.Attributes["stdin"], "@"); ok {
+		block.Stdin = s.results[ref].Out
+	}
+	block.Code = referenceRegexp.ReplaceAllStringFunc(block.Code, func(match string) string {
+		groups := referenceRegexp.FindStringSubmatch(match)
+		res := s.results[groups[1]]
+		switch groups[2] {
+		case "ExitCode":
+			return fmt.Sprint(res.ExitCode)
+		case "ExecutionTime":
+			return res.ExecutionTime.String()
+		default:
+			return res.Out
+		}
+	})
+
+	res := Execute(block)
+	if name != "" {
+		s.results[name] = res
+	}
+	return res
+}
+
+// Dependencies returns the names of the blocks the given block depends on in
+// the order they are referenced.
+func Dependencies(block Block) []string {
+	var dependencies []string
+	seen := map[string]bool{}
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			dependencies = append(dependencies, name)
+		}
+	}
+
+	if ref, ok := strings.CutPrefix(block.Attributes["stdin"], "@"); ok {
+		add(ref)
+	}
+	for _, groups := range referenceRegexp.FindAllStringSubmatch(block.Code, -1) {
+		add(groups[1])
+	}
+	return dependencies
+}
@@ -0,0 +1,65 @@
+package code_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/c0rydoras/folien/internal/code"
+)
+
+func TestDependencies(t *testing.T) {
+	block := code.Block{
+		Code:       "echo {{ .Blocks.first.Out }} {{.Blocks.second.ExitCode}} {{ .Blocks.first.Out }}",
+		Language:   "bash",
+		Attributes: map[string]string{"stdin": "@third"},
+	}
+
+	expected := []string{"third", "first", "second"}
+	if got := code.Dependencies(block); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Dependencies() = %v, want %v", got, expected)
+	}
+}
+
+func TestSessionExecute(t *testing.T) {
+	folien := []string{
+		"~~~bash name=generate\necho -e 'b\\na'\n~~~",
+		"~~~bash name=sort stdin=@generate\nsort\n~~~",
+		"~~~bash\necho \"sorted: {{ .Blocks.sort.Out }}\"\n~~~",
+		"~~~bash name=loop stdin=@loop\ncat\n~~~",
+		"~~~bash\necho {{ .Blocks.missing.Out }}\n~~~",
+	}
+
+	session := code.NewSession(folien)
+
+	tt := []struct {
+		slide    int
+		expected code.Result
+	}{
+		{
+			slide:    2,
+			expected: code.Result{Out: "sorted: a\nb\n\n"},
+		},
+		{
+			slide:    3,
+			expected: code.Result{Out: `Error: dependency cycle through block "loop"`, ExitCode: code.ExitCodeInternalError},
+		},
+		{
+			slide:    4,
+			expected: code.Result{Out: `Error: unknown block "missing"`, ExitCode: code.ExitCodeInternalError},
+		},
+	}
+
+	for _, tc := range tt {
+		blocks, err := code.Parse(folien[tc.slide])
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := session.Execute(blocks[0])
+		if r.Out != tc.expected.Out {
+			t.Fatalf("invalid output for slide %d, got %q, want %q", tc.slide, r.Out, tc.expected.Out)
+		}
+		if r.ExitCode != tc.expected.ExitCode {
+			t.Fatalf("unexpected exit code, got %d, want %d", r.ExitCode, tc.expected.ExitCode)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package mermaid
+
+import "strings"
+
+// direction bits of line segments through a cell
+const (
+	up = 1 << iota
+	down
+	left
+	right
+)
+
+// lineStyle is the style of an edge.
+type lineStyle int
+
+const (
+	solid lineStyle = iota
+	dotted
+	thick
+)
+
+// cellKind describes what a cell contains, lines are only drawn into empty
+// cells and cells containing lines, so they never cross boxes.
+type cellKind int
+
+const (
+	empty cellKind = iota
+	line
+	fixed
+)
+
+type cell struct {
+	r     rune
+	kind  cellKind
+	dirs  int
+	style lineStyle
+}
+
+// canvas is a grid of characters which grows as needed.
+type canvas struct {
+	cells [][]cell
+}
+
+func (c *canvas) at(x, y int) *cell {
+	for len(c.cells) <= y {
+		c.cells = append(c.cells, nil)
+	}
+	for len(c.cells[y]) <= x {
+		c.cells[y] = append(c.cells[y], cell{r: ' '})
+	}
+	return &c.cells[y][x]
+}
+
+// set puts a character into a cell, it replaces lines but not other
+// characters unless force is set.
+func (c *canvas) set(x, y int, r rune, force bool) {
+	if x < 0 || y < 0 {
+		return
+	}
+	cl := c.at(x, y)
+	if cl.kind == fixed && !force {
+		return
+	}
+	*cl = cell{r: r, kind: fixed}
+}
+
+// text writes s starting at x, see set.
+func (c *canvas) text(x, y int, s string, force bool) {
+	for i, r := range []rune(s) {
+		c.set(x+i, y, r, force)
+	}
+}
+
+// box draws a box with the label in its middle. Rounded boxes have rounded
+// corners, decisions have double lines.
+func (c *canvas) box(x, y, w int, label string, shape shape) {
+	corners := [4]rune{'┌', '┐', '└', '┘'}
+	horizontal, vertical := '─', '│'
+	switch shape {
+	case rounded:
+		corners = [4]rune{'╭', '╮', '╰', '╯'}
+	case decision:
+		corners = [4]rune{'╔', '╗', '╚', '╝'}
+		horizontal, vertical = '═', '║'
+	}
+
+	c.set(x, y, corners[0], true)
+	c.set(x+w-1, y, corners[1], true)
+	c.set(x, y+2, corners[2], true)
+	c.set(x+w-1, y+2, corners[3], true)
+	for i := 1; i < w-1; i++ {
+		c.set(x+i, y, horizontal, true)
+		c.set(x+i, y+1, ' ', true)
+		c.set(x+i, y+2, horizontal, true)
+	}
+	c.set(x, y+1, vertical, true)
+	c.set(x+w-1, y+1, vertical, true)
+	c.text(x+(w-runeLen(label))/2, y+1, label, true)
+}
+
+// path draws a line through the given points, consecutive points must be on
+// the same row or column.
+func (c *canvas) path(style lineStyle, points ...[2]int) {
+	for i := 0; i+1 < len(points); i++ {
+		from, to := points[i], points[i+1]
+		dx, dy := sign(to[0]-from[0]), sign(to[1]-from[1])
+		var forward, backward int
+		switch {
+		case dx > 0:
+			forward, backward = right, left
+		case dx < 0:
+			forward, backward = left, right
+		case dy > 0:
+			forward, backward = down, up
+		case dy < 0:
+			forward, backward = up, down
+		default:
+			continue
+		}
+
+		for x, y := from[0], from[1]; ; x, y = x+dx, y+dy {
+			dirs := forward | backward
+			switch {
+			case x == from[0] && y == from[1]:
+				dirs = forward
+				if i == 0 {
+					// the line starts at the box, i.e. behind the first point
+					dirs |= backward
+				}
+			case x == to[0] && y == to[1]:
+				dirs = backward
+			}
+			c.line(x, y, dirs, style)
+			if x == to[0] && y == to[1] {
+				break
+			}
+		}
+	}
+}
+
+func (c *canvas) line(x, y, dirs int, style lineStyle) {
+	if x < 0 || y < 0 {
+		return
+	}
+	cl := c.at(x, y)
+	if cl.kind == fixed {
+		return
+	}
+	cl.kind = line
+	cl.dirs |= dirs
+	if style != solid {
+		cl.style = style
+	}
+}
+
+// arrow puts an arrow head into a cell unless it contains a box.
+func (c *canvas) arrow(x, y int, r rune) {
+	if x < 0 || y < 0 || c.at(x, y).kind == fixed && c.at(x, y).r != ' ' {
+		return
+	}
+	c.set(x, y, r, true)
+}
+
+// label writes a label over empty cells and lines.
+func (c *canvas) label(x, y int, s string) {
+	for i, r := range []rune(s) {
+		if x+i < 0 || y < 0 {
+			continue
+		}
+		if cl := c.at(x+i, y); cl.kind != fixed {
+			*cl = cell{r: r, kind: fixed}
+		}
+	}
+}
+
+func (c *canvas) width() int {
+	w := 0
+	for _, row := range c.cells {
+		for x := len(row) - 1; x >= 0; x-- {
+			if row[x].kind != empty || row[x].r != ' ' {
+				w = max(w, x+1)
+				break
+			}
+		}
+	}
+	return w
+}
+
+func (c *canvas) String() string {
+	lines := make([]string, len(c.cells))
+	for y, row := range c.cells {
+		var b strings.Builder
+		for _, cl := range row {
+			if cl.kind == line {
+				b.WriteRune(lineRune(cl.dirs, cl.style))
+			} else {
+				b.WriteRune(cl.r)
+			}
+		}
+		lines[y] = strings.TrimRight(b.String(), " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// lineRune returns the box-drawing character connecting the given
+// directions.
+func lineRune(dirs int, style lineStyle) rune {
+	vertical, horizontal := '│', '─'
+	switch style {
+	case dotted:
+		vertical, horizontal = '┆', '┄'
+	case thick:
+		vertical, horizontal = '┃', '━'
+	}
+
+	switch dirs {
+	case up, down, up | down:
+		return vertical
+	case left, right, left | right:
+		return horizontal
+	case down | right:
+		return '┌'
+	case down | left:
+		return '┐'
+	case up | right:
+		return '└'
+	case up | left:
+		return '┘'
+	case up | down | right:
+		return '├'
+	case up | down | left:
+		return '┤'
+	case down | left | right:
+		return '┬'
+	case up | left | right:
+		return '┴'
+	default:
+		return '┼'
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	default:
+		return 0
+	}
+}
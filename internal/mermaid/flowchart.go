@@ -0,0 +1,546 @@
+package mermaid
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// shape is the shape of a node.
+type shape int
+
+const (
+	square shape = iota
+	rounded
+	decision
+)
+
+// shapes are the delimiters of the node shapes, longer delimiters first.
+var shapes = []struct {
+	open, close string
+	shape       shape
+}{
+	{"((", "))", rounded},
+	{"([", "])", rounded},
+	{"[[", "]]", square},
+	{"[(", ")]", square},
+	{"{{", "}}", decision},
+	{"[", "]", square},
+	{"(", ")", rounded},
+	{"{", "}", decision},
+	{">", "]", square},
+}
+
+var (
+	nodeID   = regexp.MustCompile(`^[\p{L}\p{N}_]+`)
+	class    = regexp.MustCompile(`^:::[\w-]+`)
+	textLink = regexp.MustCompile(`^(--|==|-\.)\s+(.+?)\s+(-{2,}[>ox]|={2,}[>ox]|\.-+[>ox]?|-{3,}|={3,})`)
+	link     = regexp.MustCompile(`^(<?)(-{2,}|={2,}|-\.+-)([>ox]?)(?:\|([^|]*)\|)?`)
+)
+
+// ignoredStatements are the statements of flowcharts which do not change the
+// drawing.
+var ignoredStatements = []string{"classDef", "class", "style", "linkStyle", "click", "direction"}
+
+// unsupportedStatements are the statements of flowcharts which cannot be
+// drawn.
+var unsupportedStatements = []string{"subgraph", "end"}
+
+type node struct {
+	label string
+	shape shape
+	rank  int
+	order int
+}
+
+type edge struct {
+	from, to int
+	label    string
+	style    lineStyle
+	head     rune
+	both     bool
+	// back is set for the edges which were reversed to break cycles
+	back bool
+}
+
+type flowchart struct {
+	nodes     []*node
+	ids       map[string]int
+	edges     []edge
+	direction string
+}
+
+func parseFlowchart(lines []string, direction string) (*flowchart, error) {
+	switch direction {
+	case "TB":
+		direction = "TD"
+	case "TD", "BT", "LR", "RL":
+	default:
+		return nil, fmt.Errorf("%w: flowchart direction %s", ErrUnsupported, direction)
+	}
+
+	f := &flowchart{ids: map[string]int{}, direction: direction}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if slices.Contains(unsupportedStatements, fields[0]) {
+			return nil, fmt.Errorf("%w: %s in flowchart", ErrUnsupported, fields[0])
+		}
+		if slices.Contains(ignoredStatements, fields[0]) {
+			continue
+		}
+		if err := f.parseStatement(line); err != nil {
+			return nil, err
+		}
+	}
+	if len(f.nodes) == 0 {
+		return nil, fmt.Errorf("%w: empty flowchart", ErrUnsupported)
+	}
+	if len(f.nodes) > maxElements || len(f.edges) > maxElements {
+		return nil, fmt.Errorf("%w: more than %d nodes or links", ErrUnsupported, maxElements)
+	}
+	f.rank()
+	return f, nil
+}
+
+// parseStatement parses chains of nodes and links, e.g.
+//
+//	A[Start] --> B{Valid?} -->|yes| C & D
+func (f *flowchart) parseStatement(line string) error {
+	var previous []int
+	var pending *edge
+	rest := strings.TrimSpace(line)
+	for {
+		group, r, err := f.parseNodes(rest)
+		if err != nil {
+			return fmt.Errorf("%w: %q", err, line)
+		}
+		if pending != nil {
+			for _, from := range previous {
+				for _, to := range group {
+					if from == to {
+						return fmt.Errorf("%w: link from %s to itself", ErrUnsupported, f.nodes[from].label)
+					}
+					e := *pending
+					e.from, e.to = from, to
+					f.edges = append(f.edges, e)
+				}
+			}
+		}
+
+		rest = strings.TrimSpace(r)
+		if rest == "" {
+			return nil
+		}
+		pending, rest = parseLink(rest)
+		if pending == nil {
+			return fmt.Errorf("%w: invalid link in %q", ErrUnsupported, line)
+		}
+		rest = strings.TrimSpace(rest)
+		previous = group
+	}
+}
+
+// parseNodes parses nodes separated by ampersands.
+func (f *flowchart) parseNodes(s string) ([]int, string, error) {
+	var group []int
+	for {
+		i, rest, err := f.parseNode(s)
+		if err != nil {
+			return nil, "", err
+		}
+		group = append(group, i)
+
+		rest = strings.TrimSpace(rest)
+		next, ok := strings.CutPrefix(rest, "&")
+		if !ok {
+			return group, rest, nil
+		}
+		s = strings.TrimSpace(next)
+	}
+}
+
+// parseNode parses a node and returns its index, nodes which are used for
+// the first time are added.
+func (f *flowchart) parseNode(s string) (int, string, error) {
+	id := nodeID.FindString(s)
+	if id == "" {
+		return 0, "", fmt.Errorf("%w: expected node", ErrUnsupported)
+	}
+	rest := s[len(id):]
+
+	i, ok := f.ids[id]
+	if !ok {
+		i = len(f.nodes)
+		f.ids[id] = i
+		f.nodes = append(f.nodes, &node{label: id})
+	}
+
+	for _, sh := range shapes {
+		after, ok := strings.CutPrefix(rest, sh.open)
+		if !ok {
+			continue
+		}
+		end := strings.Index(after, sh.close)
+		if end < 0 {
+			return 0, "", fmt.Errorf("%w: unterminated node %s", ErrUnsupported, id)
+		}
+		label := strings.Trim(strings.TrimSpace(after[:end]), `"`)
+		if label != "" {
+			f.nodes[i].label = label
+		}
+		f.nodes[i].shape = sh.shape
+		rest = after[end+len(sh.close):]
+		break
+	}
+	rest = strings.TrimPrefix(rest, class.FindString(rest))
+	return i, rest, nil
+}
+
+// parseLink parses a link, the returned edge is nil if s does not start with
+// a link.
+func parseLink(s string) (*edge, string) {
+	var e edge
+	var arrow string
+	if m := textLink.FindStringSubmatch(s); m != nil {
+		e.label, arrow = m[2], m[1]+m[3]
+		s = s[len(m[0]):]
+	} else if m := link.FindStringSubmatch(s); m != nil {
+		e.both, arrow, e.label = m[1] != "", m[2]+m[3], m[4]
+		s = s[len(m[0]):]
+	} else {
+		return nil, s
+	}
+
+	switch {
+	case strings.HasPrefix(arrow, "="):
+		e.style = thick
+	case strings.Contains(arrow, "."):
+		e.style = dotted
+	}
+	switch arrow[len(arrow)-1] {
+	case '>':
+		e.head = '>'
+	case 'o':
+		e.head = '○'
+	case 'x':
+		e.head = '×'
+	}
+	e.label = strings.Trim(strings.TrimSpace(e.label), `"`)
+	return &e, s
+}
+
+// rank assigns the nodes to ranks by the longest path leading to them after
+// reversing the edges closing cycles, and orders the nodes of each rank by
+// the position of their predecessors.
+func (f *flowchart) rank() {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(f.nodes))
+	var order []int
+	var visit func(int)
+	visit = func(n int) {
+		state[n] = visiting
+		for i := range f.edges {
+			e := &f.edges[i]
+			if e.from != n {
+				continue
+			}
+			switch state[e.to] {
+			case unvisited:
+				visit(e.to)
+			case visiting:
+				e.back = true
+			}
+		}
+		state[n] = visited
+		order = append(order, n)
+	}
+	for n := range f.nodes {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+
+	slices.Reverse(order)
+	for _, n := range order {
+		for _, e := range f.edges {
+			if e.from == n && !e.back {
+				f.nodes[e.to].rank = max(f.nodes[e.to].rank, f.nodes[n].rank+1)
+			}
+		}
+	}
+
+	for _, rank := range f.ranks() {
+		position := make(map[int]float64, len(rank))
+		for i, n := range rank {
+			var sum, count float64
+			for _, e := range f.edges {
+				if e.to == n && !e.back && f.nodes[e.from].rank == f.nodes[n].rank-1 {
+					sum += float64(f.nodes[e.from].order)
+					count++
+				}
+			}
+			position[n] = float64(i)
+			if count > 0 {
+				position[n] = sum / count
+			}
+		}
+		slices.SortStableFunc(rank, func(a, b int) int { return cmp.Compare(position[a], position[b]) })
+		for i, n := range rank {
+			f.nodes[n].order = i
+		}
+	}
+}
+
+// ranks returns the nodes of each rank in their order.
+func (f *flowchart) ranks() [][]int {
+	var ranks [][]int
+	for n, node := range f.nodes {
+		for len(ranks) <= node.rank {
+			ranks = append(ranks, nil)
+		}
+		ranks[node.rank] = append(ranks[node.rank], n)
+	}
+	for _, rank := range ranks {
+		slices.SortStableFunc(rank, func(a, b int) int { return cmp.Compare(f.nodes[a].order, f.nodes[b].order) })
+	}
+	return ranks
+}
+
+// rotated returns the flowchart drawn in the other orientation, vertical
+// flowcharts become horizontal ones and vice versa.
+func (f *flowchart) rotated() *flowchart {
+	r := *f
+	r.direction = map[string]string{"TD": "LR", "BT": "RL", "LR": "TD", "RL": "BT"}[f.direction]
+	return &r
+}
+
+func (f *flowchart) longestLabel() int {
+	longest := 0
+	for _, n := range f.nodes {
+		longest = max(longest, runeLen(n.label))
+	}
+	for _, e := range f.edges {
+		longest = max(longest, runeLen(e.label))
+	}
+	return longest
+}
+
+// box is the position of a node in the layout. Positions are given along the
+// main axis, the direction of the flow, and the cross axis.
+type box struct {
+	main, cross         int
+	mainSize, crossSize int
+	label               string
+}
+
+func (b box) mainEnd() int  { return b.main + b.mainSize - 1 }
+func (b box) crossEnd() int { return b.cross + b.crossSize - 1 }
+func (b box) mainMid() int  { return b.main + b.mainSize/2 }
+func (b box) crossMid() int { return b.cross + b.crossSize/2 }
+
+// draw lays out the flowchart and draws it. The layout is computed along the
+// main and cross axis and transposed for horizontal flowcharts.
+func (f *flowchart) draw(maxLabel int) *canvas {
+	horizontal := f.direction == "LR" || f.direction == "RL"
+	flow := 1
+	if f.direction == "BT" || f.direction == "RL" {
+		flow = -1
+	}
+
+	longestEdgeLabel := 0
+	for _, e := range f.edges {
+		longestEdgeLabel = max(longestEdgeLabel, runeLen(shorten(e.label, maxLabel)))
+	}
+
+	boxes := make([]box, len(f.nodes))
+	for i, n := range f.nodes {
+		label := shorten(n.label, maxLabel)
+		boxes[i] = box{mainSize: 3, crossSize: runeLen(label) + 4, label: label}
+		if horizontal {
+			boxes[i].mainSize, boxes[i].crossSize = boxes[i].crossSize, boxes[i].mainSize
+		}
+	}
+
+	// the gap between ranks holds the exit of the edges, the channel in which
+	// they turn, the labels and the arrows
+	gap, crossGap := 3, 3
+	switch {
+	case horizontal && longestEdgeLabel > 0:
+		gap, crossGap = longestEdgeLabel+7, 1
+	case horizontal:
+		gap, crossGap = 5, 1
+	case longestEdgeLabel > 0:
+		gap = 4
+	}
+
+	ranks := f.ranks()
+	rankStart := make([]int, len(ranks))
+	rankEnd := make([]int, len(ranks))
+	extents := make([]int, len(ranks))
+	position := 0
+	for i := range ranks {
+		r := i
+		if flow < 0 {
+			r = len(ranks) - 1 - i
+		}
+		size := 0
+		for _, n := range ranks[r] {
+			size = max(size, boxes[n].mainSize)
+			extents[r] += boxes[n].crossSize + crossGap
+		}
+		extents[r] -= crossGap
+		rankStart[r], rankEnd[r] = position, position+size-1
+		position += size + gap
+	}
+
+	axis := slices.Max(extents) / 2
+	for r, rank := range ranks {
+		cross := axis - extents[r]/2
+		for _, n := range rank {
+			boxes[n].main, boxes[n].cross = rankStart[r], cross
+			cross += boxes[n].crossSize + crossGap
+		}
+	}
+
+	c := &canvas{}
+	point := func(main, cross int) [2]int {
+		if horizontal {
+			return [2]int{main, cross}
+		}
+		return [2]int{cross, main}
+	}
+	arrows := map[[2]int]rune{{1, 0}: '▼', {-1, 0}: '▲', {0, -1}: '◀', {0, 1}: '▶'}
+	if horizontal {
+		arrows = map[[2]int]rune{{1, 0}: '▶', {-1, 0}: '◀', {0, -1}: '▲', {0, 1}: '▼'}
+	}
+	head := func(e edge, p [2]int, direction [2]int) {
+		if e.head == '>' {
+			c.arrow(p[0], p[1], arrows[direction])
+		} else if e.head != 0 {
+			c.arrow(p[0], p[1], e.head)
+		}
+	}
+	label := func(e edge, main, cross int) {
+		text := shorten(e.label, maxLabel)
+		if text == "" {
+			return
+		}
+		if horizontal {
+			// the label is written onto the horizontal line
+			if flow < 0 {
+				main -= runeLen(text) + 1
+			}
+			p := point(main, cross)
+			c.label(p[0], p[1], " "+text+" ")
+			return
+		}
+		p := point(main, cross)
+		c.label(p[0], p[1], text)
+	}
+
+	for n, b := range boxes {
+		x, y := b.cross, b.main
+		w := b.crossSize
+		if horizontal {
+			x, y, w = b.main, b.cross, b.mainSize
+		}
+		c.box(x, y, w, b.label, f.nodes[n].shape)
+	}
+
+	lanes := 0
+	for _, e := range f.edges {
+		if e.from == e.to {
+			continue
+		}
+		s, t := boxes[e.from], boxes[e.to]
+		sr, tr := f.nodes[e.from].rank, f.nodes[e.to].rank
+
+		if e.back {
+			// edges closing cycles leave and enter the nodes at the side
+			// and run in a lane beside the nodes they pass
+			lane := 0
+			for n, b := range boxes {
+				if r := f.nodes[n].rank; r >= min(sr, tr) && r <= max(sr, tr) {
+					lane = max(lane, b.crossEnd())
+				}
+			}
+			lane += 2 + 2*lanes
+			lanes++
+
+			points := [][2]int{
+				point(s.mainMid(), s.crossEnd()+1),
+				point(s.mainMid(), lane),
+				point(t.mainMid(), lane),
+				point(t.mainMid(), t.crossEnd()+1),
+			}
+			c.path(e.style, points...)
+			head(e, points[len(points)-1], [2]int{0, -1})
+			if e.both {
+				head(e, points[0], [2]int{0, -1})
+			}
+			if horizontal {
+				label(e, min(s.mainMid(), t.mainMid())+1, lane)
+			} else {
+				label(e, (s.mainMid()+t.mainMid())/2, lane+2)
+			}
+			continue
+		}
+
+		exitSide, entrySide := s.mainEnd(), t.main
+		channel := rankEnd[sr] + 2
+		if flow < 0 {
+			exitSide, entrySide = s.main, t.mainEnd()
+			channel = rankStart[sr] - 2
+		}
+
+		points := [][2]int{
+			point(exitSide+flow, s.crossMid()),
+			point(channel, s.crossMid()),
+		}
+		approach := channel
+		if tr-sr > 1 && f.blocked(boxes, sr, tr, t.crossMid()) {
+			// leave the column of the target to pass the ranks in between
+			lane := 0
+			for n, b := range boxes {
+				if r := f.nodes[n].rank; r > sr && r < tr {
+					lane = max(lane, b.crossEnd()+2)
+				}
+			}
+			approach = rankEnd[tr-1] + 2
+			if flow < 0 {
+				approach = rankStart[tr-1] - 2
+			}
+			points = append(points, point(channel, lane), point(approach, lane))
+		}
+		points = append(points, point(approach, t.crossMid()), point(entrySide-flow, t.crossMid()))
+		c.path(e.style, points...)
+		head(e, points[len(points)-1], [2]int{flow, 0})
+		if e.both {
+			head(e, points[0], [2]int{-flow, 0})
+		}
+		if horizontal {
+			label(e, approach+2*flow, t.crossMid())
+		} else {
+			label(e, entrySide-2*flow, t.crossMid()+2)
+		}
+	}
+	return c
+}
+
+// blocked returns whether a node of the ranks between from and to covers the
+// position on the cross axis.
+func (f *flowchart) blocked(boxes []box, from, to, cross int) bool {
+	for n, b := range boxes {
+		r := f.nodes[n].rank
+		if r > min(from, to) && r < max(from, to) && cross >= b.cross-1 && cross <= b.crossEnd()+1 {
+			return true
+		}
+	}
+	return false
+}
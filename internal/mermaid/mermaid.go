@@ -0,0 +1,135 @@
+// Package mermaid renders a subset of Mermaid diagrams, flowcharts and
+// sequence diagrams, with box-drawing characters.
+package mermaid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Language is the language of code blocks containing Mermaid diagrams.
+const Language = "mermaid"
+
+const (
+	// minLabelWidth is the width labels are shortened to at most when fitting
+	// a diagram into the available width.
+	minLabelWidth = 6
+	// maxLabelWidth and maxElements limit the size of the diagrams which are
+	// drawn, larger diagrams are shown as source as they would neither fit on
+	// a slide nor be drawn quickly.
+	maxLabelWidth = 80
+	maxElements   = 50
+)
+
+// ErrUnsupported is returned for diagram types which cannot be rendered.
+var ErrUnsupported = errors.New("unsupported diagram")
+
+// diagram is a parsed diagram which can be drawn with labels of the given
+// maximum width.
+type diagram interface {
+	draw(maxLabel int) *canvas
+	longestLabel() int
+}
+
+// Render renders the Mermaid diagram with at most width columns if possible.
+// Flowcharts are drawn in the other direction and labels are shortened if the
+// diagram is too wide.
+func Render(source string, width int) (string, error) {
+	lines := diagramLines(source)
+	if len(lines) == 0 {
+		return "", fmt.Errorf("%w: empty diagram", ErrUnsupported)
+	}
+
+	var candidates []diagram
+	header := strings.Fields(lines[0])
+	switch header[0] {
+	case "flowchart", "graph":
+		direction := "TD"
+		if len(header) > 1 {
+			direction = strings.ToUpper(header[1])
+		}
+		f, err := parseFlowchart(lines[1:], direction)
+		if err != nil {
+			return "", err
+		}
+		candidates = append(candidates, f, f.rotated())
+	case "sequenceDiagram":
+		s, err := parseSequence(lines[1:])
+		if err != nil {
+			return "", err
+		}
+		candidates = append(candidates, s)
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupported, header[0])
+	}
+
+	longest := 0
+	for _, d := range candidates {
+		longest = max(longest, d.longestLabel())
+	}
+	if longest > maxLabelWidth {
+		return "", fmt.Errorf("%w: labels longer than %d characters", ErrUnsupported, maxLabelWidth)
+	}
+
+	// prefer complete labels over the orientation of flowcharts: find the
+	// longest labels which fit with a binary search, narrower labels never
+	// make a diagram wider
+	fits := func(maxLabel int) *canvas {
+		for _, d := range candidates {
+			if c := d.draw(maxLabel); c.width() <= width {
+				return c
+			}
+		}
+		return nil
+	}
+	var best *canvas
+	low, high := min(minLabelWidth, longest), longest
+	for low <= high {
+		mid := (low + high) / 2
+		if c := fits(mid); c != nil {
+			best, low = c, mid+1
+		} else {
+			high = mid - 1
+		}
+	}
+	if best != nil {
+		return best.String(), nil
+	}
+
+	// nothing fits, the narrowest drawing is cut off at the edge
+	var narrowest *canvas
+	for _, d := range candidates {
+		if c := d.draw(min(minLabelWidth, longest)); narrowest == nil || c.width() < narrowest.width() {
+			narrowest = c
+		}
+	}
+	return narrowest.String(), nil
+}
+
+// diagramLines returns the lines of the diagram without comments and blank
+// lines.
+func diagramLines(source string) []string {
+	var lines []string
+	for _, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), ";"))
+		if line == "" || strings.HasPrefix(line, "%%") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// shorten shortens the label to at most maxWidth runes.
+func shorten(label string, maxWidth int) string {
+	runes := []rune(label)
+	if len(runes) <= maxWidth {
+		return label
+	}
+	return string(runes[:max(maxWidth-1, 0)]) + "…"
+}
+
+func runeLen(s string) int {
+	return len([]rune(s))
+}
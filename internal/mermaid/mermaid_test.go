@@ -0,0 +1,190 @@
+package mermaid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			name:   "Top down flowchart",
+			source: "flowchart TD\n  A[Start] --> B(Stop)\n",
+			expected: `┌───────┐
+│ Start │
+└───────┘
+    │
+    │
+    ▼
+╭──────╮
+│ Stop │
+╰──────╯
+`,
+		},
+		{
+			name:   "Left right flowchart with label",
+			source: "graph LR;\n  A[Start] -->|go| B{Stop};\n",
+			expected: `┌───────┐         ╔══════╗
+│ Start │─── go ─▶║ Stop ║
+└───────┘         ╚══════╝
+`,
+		},
+		{
+			name:   "Sequence diagram",
+			source: "sequenceDiagram\n  %% greeting\n  Alice->>Bob: Hi\n  Bob-->>Alice: Hello\n",
+			expected: `┌───────┐  ┌─────┐
+│ Alice │  │ Bob │
+└───────┘  └─────┘
+    │         │
+    │ Hi      │
+    ├────────▶│
+    │         │
+    │ Hello   │
+    │◀┄┄┄┄┄┄┄┄┤
+    │         │
+┌───────┐  ┌─────┐
+│ Alice │  │ Bob │
+└───────┘  └─────┘
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Render(tt.source, 80)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != tt.expected {
+				t.Errorf("Render() =\n%s\nwant\n%s", out, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderFitsWidth(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		width  int
+	}{
+		{
+			name:   "Flowchart is rotated",
+			source: "flowchart LR\n  A[Request] --> B[Authenticate] --> C[Authorize] --> D[Respond]",
+			width:  40,
+		},
+		{
+			name:   "Labels are shortened",
+			source: "sequenceDiagram\n  participant C as Client\n  participant S as Server\n  C->>S: A rather long request message\n",
+			width:  30,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Render(tt.source, tt.width)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, line := range strings.Split(out, "\n") {
+				if utf8.RuneCountInString(line) > tt.width {
+					t.Errorf("line %q is wider than %d columns", line, tt.width)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderCycle(t *testing.T) {
+	out, err := Render("flowchart TD\n  A --> B --> C\n  C -.->|retry| A", 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"◀", "retry", "┆"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+}
+
+// chain returns a flowchart of n nodes in a chain with labels of the given
+// length.
+func chain(n, label int) string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i := range n - 1 {
+		fmt.Fprintf(&b, "  N%d[%s] --> N%d\n", i, strings.Repeat("x", label), i+1)
+	}
+	return b.String()
+}
+
+func TestRenderLargest(t *testing.T) {
+	start := time.Now()
+	if _, err := Render(chain(maxElements, maxLabelWidth), 80); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("rendering took %s", elapsed)
+	}
+}
+
+func TestRenderUnsupported(t *testing.T) {
+	for _, source := range []string{
+		chain(maxElements+2, 1),
+		chain(2, maxLabelWidth+1),
+		"",
+		"pie\n  \"A\": 1",
+		"flowchart TD\n  A --> ",
+		"flowchart TD\n  A --> B --> B",
+		"flowchart TD\n  subgraph one\n    A --> B\n  end",
+		"sequenceDiagram\n  Alice Bob",
+		"sequenceDiagram\n  Alice->>Bob: Hi\n  Note right of Bob: Thinks",
+		"sequenceDiagram\n  loop Every minute\n    Alice->>Bob: Ping\n  end",
+		"sequenceDiagram\n  alt ok\n    Bob->>Alice: Yes\n  else\n    Bob->>Alice: No\n  end",
+	} {
+		if _, err := Render(source, 80); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Render(%q) error = %v, want %v", source, err, ErrUnsupported)
+		}
+	}
+}
+
+func TestParseLink(t *testing.T) {
+	tests := []struct {
+		link     string
+		expected edge
+	}{
+		{"-->", edge{head: '>'}},
+		{"---", edge{}},
+		{"-.->", edge{style: dotted, head: '>'}},
+		{"==>", edge{style: thick, head: '>'}},
+		{"--x", edge{head: '×'}},
+		{"<-->", edge{head: '>', both: true}},
+		{"-->|yes|", edge{head: '>', label: "yes"}},
+		{"-- no -->", edge{head: '>', label: "no"}},
+		{"-. maybe .->", edge{style: dotted, head: '>', label: "maybe"}},
+		{"== sure ==>", edge{style: thick, head: '>', label: "sure"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			e, rest := parseLink(tt.link + " B")
+			if e == nil {
+				t.Fatal("no link")
+			}
+			if *e != tt.expected {
+				t.Errorf("parseLink() = %+v, want %+v", *e, tt.expected)
+			}
+			if rest != " B" {
+				t.Errorf("rest = %q, want %q", rest, " B")
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package mermaid
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// messageArrows are the arrows of messages, longer arrows first.
+var messageArrows = []struct {
+	arrow string
+	style lineStyle
+	head  rune
+}{
+	{"-->>", dotted, '>'},
+	{"->>", solid, '>'},
+	{"--x", dotted, '×'},
+	{"-x", solid, '×'},
+	{"--)", dotted, ')'},
+	{"-)", solid, ')'},
+	{"-->", dotted, 0},
+	{"->", solid, 0},
+}
+
+var participantPattern = regexp.MustCompile(`^(participant|actor)\s+(.+?)(?:\s+as\s+(.+))?$`)
+
+// ignoredSequenceStatements are the statements of sequence diagrams which do
+// not change the drawing.
+var ignoredSequenceStatements = []string{"activate", "deactivate", "autonumber", "title", "create", "destroy"}
+
+// unsupportedSequenceStatements are the statements of sequence diagrams which
+// cannot be drawn, notes and blocks around messages.
+var unsupportedSequenceStatements = []string{
+	"Note", "note", "loop", "alt", "else", "opt", "par", "and", "critical", "option", "break",
+	"rect", "end", "box",
+}
+
+type participant struct {
+	id, label string
+}
+
+type message struct {
+	from, to int
+	label    string
+	style    lineStyle
+	head     rune
+}
+
+type sequence struct {
+	participants []participant
+	messages     []message
+}
+
+func parseSequence(lines []string) (*sequence, error) {
+	s := &sequence{}
+	for _, line := range lines {
+		if m := participantPattern.FindStringSubmatch(line); m != nil {
+			i := s.participant(m[2])
+			if m[3] != "" {
+				s.participants[i].label = m[3]
+			}
+			continue
+		}
+		fields := strings.Fields(line)
+		if slices.Contains(unsupportedSequenceStatements, fields[0]) {
+			return nil, fmt.Errorf("%w: %s in sequence diagram", ErrUnsupported, fields[0])
+		}
+		if slices.Contains(ignoredSequenceStatements, fields[0]) {
+			continue
+		}
+		if err := s.parseMessage(line); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.participants) == 0 {
+		return nil, fmt.Errorf("%w: empty sequence diagram", ErrUnsupported)
+	}
+	if len(s.participants) > maxElements || len(s.messages) > maxElements {
+		return nil, fmt.Errorf("%w: more than %d participants or messages", ErrUnsupported, maxElements)
+	}
+	return s, nil
+}
+
+// parseMessage parses a message, e.g.
+//
+//	Alice->>+Bob: Hello
+func (s *sequence) parseMessage(line string) error {
+	participants, label, _ := strings.Cut(line, ":")
+	for i := strings.IndexByte(participants, '-'); i > 0; i = next(participants, i) {
+		for _, a := range messageArrows {
+			if !strings.HasPrefix(participants[i:], a.arrow) {
+				continue
+			}
+			from := strings.TrimSpace(participants[:i])
+			to := strings.TrimLeft(strings.TrimSpace(participants[i+len(a.arrow):]), "+-")
+			if from == "" || to == "" {
+				break
+			}
+			s.messages = append(s.messages, message{
+				from:  s.participant(from),
+				to:    s.participant(to),
+				label: strings.TrimSpace(label),
+				style: a.style,
+				head:  a.head,
+			})
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: invalid message %q", ErrUnsupported, line)
+}
+
+func next(s string, i int) int {
+	j := strings.IndexByte(s[i+1:], '-')
+	if j < 0 {
+		return -1
+	}
+	return i + 1 + j
+}
+
+// participant returns the index of the participant, participants are added
+// when they are used for the first time.
+func (s *sequence) participant(id string) int {
+	i := slices.IndexFunc(s.participants, func(p participant) bool { return p.id == id })
+	if i < 0 {
+		i = len(s.participants)
+		s.participants = append(s.participants, participant{id: id, label: id})
+	}
+	return i
+}
+
+func (s *sequence) longestLabel() int {
+	longest := 0
+	for _, p := range s.participants {
+		longest = max(longest, runeLen(p.label))
+	}
+	for _, m := range s.messages {
+		longest = max(longest, runeLen(m.label))
+	}
+	return longest
+}
+
+// draw draws the participants at the top and the bottom, connected by their
+// lifelines, and the messages from top to bottom in between.
+func (s *sequence) draw(maxLabel int) *canvas {
+	labels := make([]string, len(s.participants))
+	centers := make([]int, len(s.participants))
+	for i, p := range s.participants {
+		labels[i] = shorten(p.label, maxLabel)
+		w := runeLen(labels[i]) + 4
+		if i == 0 {
+			centers[i] = w / 2
+			continue
+		}
+		previous := runeLen(labels[i-1]) + 4
+		centers[i] = centers[i-1] + previous - previous/2 + w/2 + 2
+	}
+
+	// move the participants apart until the labels of the messages fit
+	// between them
+	for _, m := range s.messages {
+		from, to := min(m.from, m.to), max(m.from, m.to)
+		needed := runeLen(shorten(m.label, maxLabel)) + 4
+		if from == to {
+			if to = from + 1; to == len(centers) {
+				continue
+			}
+			needed += 4
+		}
+		if missing := needed - (centers[to] - centers[from]); missing > 0 {
+			for i := to; i < len(centers); i++ {
+				centers[i] += missing
+			}
+		}
+	}
+
+	c := &canvas{}
+	y := 4
+	for _, m := range s.messages {
+		label := shorten(m.label, maxLabel)
+		from, to := centers[m.from], centers[m.to]
+		if m.from == m.to {
+			c.label(from+2, y, label)
+			c.line(from, y+1, right, solid)
+			c.path(m.style, [2]int{from + 1, y + 1}, [2]int{from + 4, y + 1}, [2]int{from + 4, y + 2}, [2]int{from + 1, y + 2})
+			s.head(c, m, from+1, y+2, -1)
+			y += 4
+			continue
+		}
+
+		direction, towards := 1, right
+		if to < from {
+			direction, towards = -1, left
+		}
+		c.label(min(from, to)+2, y, label)
+		c.line(from, y+1, towards, solid)
+		for x := from + direction; x != to; x += direction {
+			c.line(x, y+1, left|right, m.style)
+		}
+		if m.head == 0 {
+			c.line(to, y+1, towards^(left|right), solid)
+		} else {
+			s.head(c, m, to-direction, y+1, direction)
+		}
+		y += 3
+	}
+
+	for i, center := range centers {
+		w := runeLen(labels[i]) + 4
+		c.box(center-w/2, 0, w, labels[i], square)
+		c.box(center-w/2, y, w, labels[i], square)
+		c.path(solid, [2]int{center, 3}, [2]int{center, y - 1})
+	}
+	return c
+}
+
+func (s *sequence) head(c *canvas, m message, x, y, direction int) {
+	if m.head == 0 {
+		return
+	}
+	heads := map[rune][2]rune{'>': {'◀', '▶'}, '×': {'×', '×'}, ')': {'◁', '▷'}}
+	h := heads[m.head][0]
+	if direction > 0 {
+		h = heads[m.head][1]
+	}
+	c.arrow(x, y, h)
+}
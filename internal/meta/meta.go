@@ -5,19 +5,62 @@ package meta
 import (
 	"os"
 	"os/user"
+	"reflect"
 	"strings"
 	"time"
 
+	"github.com/c0rydoras/folien/internal/redact"
 	"github.com/c0rydoras/folien/pkg/parser"
 )
 
 // Meta contains all of the data to be parsed
 // out of a markdown file's header section
 type Meta struct {
+	Title  string `yaml:"title"`
 	Theme  string `yaml:"theme"`
 	Author string `yaml:"author"`
 	Date   string `yaml:"date"`
 	Paging string `yaml:"paging"`
+	// Redact configures secrets which are masked in the folien and in the
+	// output of executed code blocks.
+	Redact redact.Config `yaml:"redact"`
+	// Layout configures where the output of executed code blocks is
+	// displayed.
+	Layout Layout `yaml:"layout"`
+	// Vars contains the variables available in templates.
+	Vars map[string]any `yaml:"vars"`
+	// Headings configures how headings are inherited by the following
+	// folien.
+	Headings Headings `yaml:"headings"`
+	// Pipeline is the order of the preprocessor stages, including external
+	// filters.
+	Pipeline []string `yaml:"pipeline"`
+	// Renderers maps the languages of code blocks to commands rendering
+	// them when they are displayed.
+	Renderers map[string]string `yaml:"renderers"`
+	// Bibliography is the BibTeX or CSL-JSON file citations are resolved
+	// against.
+	Bibliography string `yaml:"bibliography"`
+	// Incremental reveals the items of lists one at a time.
+	Incremental bool `yaml:"incremental"`
+	// Separator separates the folien, see parser.Split.
+	Separator string `yaml:"separator"`
+}
+
+// Headings contains the deepest inherited heading level, whether the
+// inherited headings are added to the slide (inline) or displayed in the
+// status bar (breadcrumb) and whether headings are numbered.
+type Headings struct {
+	Depth    int    `yaml:"depth"`
+	Mode     string `yaml:"mode"`
+	Numbered bool   `yaml:"numbered"`
+}
+
+// Layout contains the layout mode (inline, horizontal or vertical) and the
+// share of the slide in split layouts.
+type Layout struct {
+	Mode  string  `yaml:"mode"`
+	Ratio float64 `yaml:"ratio"`
 }
 
 // New creates a new instance of the
@@ -45,10 +88,21 @@ func (m *Meta) Parse(presentation string) (*Meta, bool) {
 	}
 
 	// If all fields are empty, assume no frontmatter was found
-	if tmp.Theme == "" && tmp.Author == "" && tmp.Date == "" && tmp.Paging == "" {
+	if reflect.ValueOf(tmp).IsZero() {
 		return fallback, false
 	}
 
+	m.Title = tmp.Title
+	m.Redact = tmp.Redact
+	m.Layout = tmp.Layout
+	m.Vars = tmp.Vars
+	m.Headings = tmp.Headings
+	m.Pipeline = tmp.Pipeline
+	m.Renderers = tmp.Renderers
+	m.Bibliography = tmp.Bibliography
+	m.Incremental = tmp.Incremental
+	m.Separator = tmp.Separator
+
 	if tmp.Theme != "" {
 		m.Theme = tmp.Theme
 	} else {
@@ -62,7 +116,7 @@ func (m *Meta) Parse(presentation string) (*Meta, bool) {
 	}
 
 	if tmp.Date != "" {
-		parsedDate := parseDate(tmp.Date)
+		parsedDate := DateLayout(tmp.Date)
 		if parsedDate == tmp.Date {
 			m.Date = tmp.Date
 		} else {
@@ -99,14 +153,16 @@ func defaultAuthor() string {
 }
 
 func defaultDate() string {
-	return time.Now().Format(parseDate("YYYY-MM-DD"))
+	return time.Now().Format(DateLayout("YYYY-MM-DD"))
 }
 
 func defaultPaging() string {
 	return "Slide %d / %d"
 }
 
-func parseDate(value string) string {
+// DateLayout converts a date format like YYYY-MM-DD into the layout used by
+// time.Format.
+func DateLayout(value string) string {
 	pairs := [][]string{
 		{"YYYY", "2006"},
 		{"YY", "06"},
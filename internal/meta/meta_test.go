@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/c0rydoras/folien/internal/meta"
+	"github.com/c0rydoras/folien/internal/redact"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -149,6 +150,43 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Paging: "Slide %d / %d",
 			},
 		},
+		{
+			name:      "Parse redact from header",
+			slideshow: "---\nredact:\n  env: [GITHUB_TOKEN]\n  patterns: ['ghp_[A-Za-z0-9]+']\n---\n",
+			want: &meta.Meta{
+				Theme:  "default",
+				Author: user.Name,
+				Date:   date,
+				Paging: "Slide %d / %d",
+				Redact: redact.Config{
+					Env:      []string{"GITHUB_TOKEN"},
+					Patterns: []string{"ghp_[A-Za-z0-9]+"},
+				},
+			},
+		},
+		{
+			name:      "Parse title and vars from header",
+			slideshow: "---\ntitle: Quarterly Review\nvars:\n  customer: ACME\n  seats: 12\n---\n",
+			want: &meta.Meta{
+				Title:  "Quarterly Review",
+				Theme:  "default",
+				Author: user.Name,
+				Date:   date,
+				Paging: "Slide %d / %d",
+				Vars:   map[string]any{"customer": "ACME", "seats": 12},
+			},
+		},
+		{
+			name:      "Parse headings from header",
+			slideshow: "---\nheadings:\n  depth: 3\n  mode: breadcrumb\n---\n",
+			want: &meta.Meta{
+				Theme:    "default",
+				Author:   user.Name,
+				Date:     date,
+				Paging:   "Slide %d / %d",
+				Headings: meta.Headings{Depth: 3, Mode: "breadcrumb"},
+			},
+		},
 		{
 			name:      "Fallback if first slide is valid yaml",
 			slideshow: "---\n# Header Slide---\nContent\n",
@@ -0,0 +1,130 @@
+package model
+
+import (
+	"time"
+
+	"github.com/c0rydoras/folien/internal/code"
+	"github.com/c0rydoras/folien/internal/preprocessor"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// demoKey identifies a demo block by page and index on the slide.
+type demoKey struct {
+	page, block int
+}
+
+// demoTypeMsg types the next character of the given demo.
+type demoTypeMsg struct {
+	key demoKey
+}
+
+// demoOutputMsg contains the output of the current command of the given
+// demo.
+type demoOutputMsg struct {
+	key demoKey
+	out string
+}
+
+// demo returns the demo of the current slide which is advanced next: the
+// first demo which is not done, or the first demo once all of them are done
+// to restart it. Demos are created on first use and kept until the
+// presentation is reloaded.
+func (m *Model) demo() (demoKey, *code.Demo) {
+	blocks, err := code.Parse(m.Slides[m.Page])
+	if err != nil {
+		return demoKey{}, nil
+	}
+	var (
+		firstKey demoKey
+		first    *code.Demo
+	)
+	for i, block := range blocks {
+		if block.Language != code.DemoLanguage {
+			continue
+		}
+		key := demoKey{page: m.Page, block: i}
+		d, ok := m.demos[key]
+		if !ok {
+			d = code.NewDemo(block)
+			m.demos[key] = d
+		}
+		if !d.Done() {
+			return key, d
+		}
+		if first == nil {
+			firstKey, first = key, d
+		}
+	}
+	return firstKey, first
+}
+
+// advanceDemo starts typing the next command of the demo, if a command is
+// being typed it is completed immediately.
+func (m *Model) advanceDemo(key demoKey, d *code.Demo) tea.Cmd {
+	if d.Skip() {
+		m.updateViewportContent()
+		return m.runDemoCommand(key, d.Command())
+	}
+	if !d.Next() {
+		return nil
+	}
+	m.updateViewportContent()
+	return demoTypeCmd(key)
+}
+
+func (m *Model) handleDemoType(msg demoTypeMsg) tea.Cmd {
+	d, ok := m.demos[msg.key]
+	if !ok {
+		return nil
+	}
+	var cmd tea.Cmd
+	if d.Type() {
+		cmd = m.runDemoCommand(msg.key, d.Command())
+	} else if d.Typing() {
+		cmd = demoTypeCmd(msg.key)
+	}
+	m.updateViewportContent()
+	return cmd
+}
+
+func (m *Model) handleDemoOutput(msg demoOutputMsg) {
+	d, ok := m.demos[msg.key]
+	if !ok {
+		return
+	}
+	d.Finish(m.redactor.Redact(msg.out))
+	m.updateViewportContent()
+}
+
+func (m *Model) runDemoCommand(key demoKey, command string) tea.Cmd {
+	dir := m.workspace()
+	return func() tea.Msg {
+		return demoOutputMsg{key: key, out: code.RunShell(command, dir)}
+	}
+}
+
+func demoTypeCmd(key demoKey) tea.Cmd {
+	return tea.Tick(code.TypingDelay(), func(time.Time) tea.Msg {
+		return demoTypeMsg{key: key}
+	})
+}
+
+// renderDemos replaces the demo blocks of the slide with their transcript.
+func (m *Model) renderDemos(slide string) string {
+	return code.ReplaceBlocks(slide, func(i int, block code.Block) (string, bool) {
+		if block.Language != code.DemoLanguage {
+			return "", false
+		}
+		d, ok := m.demos[demoKey{page: m.Page, block: i}]
+		if !ok {
+			d = code.NewDemo(block)
+		}
+		return code.Fence("", d.Transcript()), true
+	})
+}
+
+// workspace returns the directory commands are executed in, which is the
+// directory containing the presentation.
+func (m *Model) workspace() string {
+	return preprocessor.BaseDir(m.FileName)
+}
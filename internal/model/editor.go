@@ -0,0 +1,123 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/c0rydoras/folien/internal/code"
+	"github.com/c0rydoras/folien/styles"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editorHelp is displayed above the editor.
+const editorHelp = "ctrl+e: run · esc: done · ctrl+c: discard"
+
+// editor edits a code block of the current slide.
+type editor struct {
+	textarea textarea.Model
+	// block is the index of the edited block on the slide
+	block int
+}
+
+// currentSlide returns the current slide with the edits of this session
+// applied to its code blocks.
+func (m *Model) currentSlide() string {
+	slide := m.Slides[m.Page]
+	for block, source := range m.edits[m.Page] {
+		slide = code.SetBlockSource(slide, block, source)
+	}
+	return slide
+}
+
+// openEditor opens the code block with the index given by the buffer (or the
+// first code block) of the current slide in the editor.
+func (m *Model) openEditor() tea.Cmd {
+	block := 0
+	if m.bufferIsNumeric() {
+		if n, err := strconv.Atoi(m.buffer); err == nil && n > 0 {
+			block = n - 1
+		}
+	}
+	m.buffer = ""
+
+	source, ok := code.BlockSource(m.currentSlide(), block)
+	if !ok {
+		return nil
+	}
+	// the editor would reveal the secrets of the block
+	if m.redactor.Redact(source) != source {
+		m.VirtualText = "\nBlocks containing secrets cannot be edited"
+		m.updateViewportContent()
+		return nil
+	}
+
+	ta := textarea.New()
+	ta.CharLimit = 0
+	ta.ShowLineNumbers = true
+	ta.SetWidth(m.viewport.Width - styles.Slide.GetHorizontalFrameSize())
+	ta.SetHeight(max(1, m.viewport.Height-styles.Slide.GetVerticalFrameSize()-2))
+	ta.SetValue(strings.TrimSuffix(source, "\n"))
+	m.editor = &editor{textarea: ta, block: block}
+	return m.editor.textarea.Focus()
+}
+
+// handleEditorKey passes the key to the editor, unless it closes the editor.
+func (m *Model) handleEditorKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		m.editor = nil
+		return nil
+	case "esc":
+		m.saveEdit()
+		return nil
+	case "ctrl+e":
+		m.saveEdit()
+		return m.executeBlocks()
+	}
+
+	var cmd tea.Cmd
+	m.editor.textarea, cmd = m.editor.textarea.Update(msg)
+	return cmd
+}
+
+// saveEdit keeps the edited code for the rest of the session and closes the
+// editor. The file on disk is not changed.
+func (m *Model) saveEdit() {
+	if m.edits[m.Page] == nil {
+		m.edits[m.Page] = map[int]string{}
+	}
+	m.edits[m.Page][m.editor.block] = m.editor.textarea.Value()
+	m.editor = nil
+	m.updateViewportContent()
+}
+
+// resetEdits discards the edits of the current slide.
+func (m *Model) resetEdits() {
+	delete(m.edits, m.Page)
+	m.VirtualText = ""
+	m.updateViewportContent()
+}
+
+// dropChangedEdits discards the edits of the folien whose source changed when
+// the presentation was reloaded, so they neither hide the changes nor apply
+// to other code blocks. The editor is closed if its slide changed.
+func (m *Model) dropChangedEdits(previous []string) {
+	changed := func(page int) bool {
+		return page >= len(previous) || page >= len(m.Slides) || previous[page] != m.Slides[page]
+	}
+	for page := range m.edits {
+		if changed(page) {
+			delete(m.edits, page)
+		}
+	}
+	if m.editor != nil && changed(m.Page) {
+		m.editor = nil
+	}
+}
+
+func (m Model) viewEditor() string {
+	title := styles.Hidden.Render(fmt.Sprintf("Editing block %d · %s", m.editor.block+1, editorHelp))
+	return styles.Slide.Render(title + "\n\n" + m.editor.textarea.View())
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/c0rydoras/folien/styles"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Layout describes where the output of executed code blocks is displayed.
+type Layout string
+
+const (
+	// LayoutInline appends the output to the slide.
+	LayoutInline Layout = "inline"
+	// LayoutHorizontal displays the output in a pane next to the slide.
+	LayoutHorizontal Layout = "horizontal"
+	// LayoutVertical displays the output in a pane below the slide.
+	LayoutVertical Layout = "vertical"
+)
+
+const (
+	// footerHeight is the height of the status bar.
+	footerHeight = 3
+	// defaultSplitRatio is the share of the slide pane in split layouts.
+	defaultSplitRatio = 0.5
+)
+
+// ParseLayout parses the name of a layout, "split" and "stacked" are accepted
+// as aliases for the horizontal and vertical layout.
+func ParseLayout(name string) (Layout, error) {
+	switch name {
+	case "", string(LayoutInline):
+		return LayoutInline, nil
+	case string(LayoutHorizontal), "split":
+		return LayoutHorizontal, nil
+	case string(LayoutVertical), "stacked":
+		return LayoutVertical, nil
+	default:
+		return "", fmt.Errorf("unknown layout %q", name)
+	}
+}
+
+// next returns the layout to switch to when cycling through the layouts.
+func (l Layout) next() Layout {
+	switch l {
+	case LayoutInline:
+		return LayoutHorizontal
+	case LayoutHorizontal:
+		return LayoutVertical
+	default:
+		return LayoutInline
+	}
+}
+
+// split returns whether the output is displayed in its own pane.
+func (m *Model) split() bool {
+	return m.layout == LayoutHorizontal || m.layout == LayoutVertical
+}
+
+// resize distributes the size of the window between the slide and the output
+// pane according to the layout.
+func (m *Model) resize(width, height int) {
+	m.width, m.height = width, height
+	height -= footerHeight
+
+	if !m.ready {
+		m.viewport = viewport.New(width, height)
+		m.viewport.YPosition = 0
+		m.output = viewport.New(0, 0)
+		m.ready = true
+	}
+
+	ratio := m.splitRatio
+	if ratio <= 0 || ratio >= 1 {
+		ratio = defaultSplitRatio
+	}
+
+	switch m.layout {
+	case LayoutHorizontal:
+		slideWidth := int(float64(width) * ratio)
+		m.viewport.Width, m.viewport.Height = slideWidth, height
+		m.output.Width = max(0, width-slideWidth-styles.Output.GetHorizontalFrameSize()-styles.Slide.GetHorizontalFrameSize())
+		m.output.Height = max(0, height-styles.Output.GetVerticalFrameSize())
+	case LayoutVertical:
+		slideHeight := int(float64(height) * ratio)
+		m.viewport.Width, m.viewport.Height = width, slideHeight
+		m.output.Width = max(0, width-styles.Output.GetHorizontalFrameSize())
+		m.output.Height = max(0, height-slideHeight-styles.Output.GetVerticalFrameSize()-styles.Slide.GetVerticalFrameSize())
+	default:
+		m.viewport.Width, m.viewport.Height = width, height
+		m.output.Width, m.output.Height = 0, 0
+		m.outputFocused = false
+	}
+
+	m.resizeTerminals()
+	m.updateViewportContent()
+}
+
+// updateOutputContent displays the output of the executed code blocks in the
+// output pane.
+func (m *Model) updateOutputContent() {
+	if !m.ready {
+		return
+	}
+	content := m.VirtualText
+	if content == "" {
+		content = styles.Hidden.Render("Press ctrl+e to execute the code blocks of this slide")
+	}
+	m.output.SetContent(lipgloss.NewStyle().Width(m.output.Width).Render(content))
+}
+
+// focusedViewport returns the viewport receiving scroll keys.
+func (m *Model) focusedViewport() *viewport.Model {
+	if m.outputFocused {
+		return &m.output
+	}
+	return &m.viewport
+}
+
+// viewPanes renders the slide and, in split layouts, the output pane.
+func (m Model) viewPanes() string {
+	slide := styles.Slide.Render(m.viewport.View())
+	if !m.split() {
+		return slide
+	}
+
+	style := styles.Output
+	if m.outputFocused {
+		style = styles.OutputFocused
+	}
+	output := style.Render(m.output.View())
+
+	if m.layout == LayoutHorizontal {
+		return styles.JoinPanes(slide, output, m.viewport.Width+styles.Slide.GetHorizontalFrameSize())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, slide, output)
+}
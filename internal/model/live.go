@@ -0,0 +1,148 @@
+package model
+
+import (
+	"time"
+
+	"github.com/c0rydoras/folien/internal/code"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// liveTickMsg checks whether live blocks of the current slide need to be
+// refreshed, it also updates the timer of the presenter view and the sync
+// status in the status bar.
+type liveTickMsg struct {
+	// generation is the generation of the ticker, the ticks of a replaced
+	// ticker are dropped
+	generation int
+}
+
+// liveOutputMsg contains the output of a live block.
+type liveOutputMsg struct {
+	key liveKey
+	out string
+}
+
+// liveKey identifies a live block by page and index on the slide.
+type liveKey struct {
+	page, block int
+}
+
+// liveBlock is the state of a live block.
+type liveBlock struct {
+	out     string
+	lastRun time.Time
+	running bool
+}
+
+func liveTickCmd(generation int) tea.Cmd {
+	return tea.Every(time.Second, func(time.Time) tea.Msg {
+		return liveTickMsg{generation: generation}
+	})
+}
+
+// ticking returns whether the tick is needed, because the presentation
+// contains live blocks or the status bar shows the timer or the sync status.
+func (m *Model) ticking() bool {
+	return (m.AllowExecution && m.hasLive) || m.Presenter || m.Remote != nil
+}
+
+// handleLiveTick refreshes the live blocks and schedules the next tick as long
+// as it is needed.
+func (m *Model) handleLiveTick(msg liveTickMsg) tea.Cmd {
+	if msg.generation != m.tickGeneration || !m.ticking() {
+		return nil
+	}
+	return tea.Batch(m.refreshLive(), liveTickCmd(m.tickGeneration))
+}
+
+// restartTick starts a new ticker if the reloaded presentation needs one while
+// the previous one did not, the ticker might have stopped already.
+func (m *Model) restartTick(wasTicking bool) tea.Cmd {
+	if wasTicking || !m.ticking() {
+		return nil
+	}
+	m.tickGeneration++
+	return liveTickCmd(m.tickGeneration)
+}
+
+// containsLive returns whether one of the folien contains a live block.
+func containsLive(folien []string) bool {
+	for _, slide := range folien {
+		blocks, _ := code.Parse(slide)
+		for _, block := range blocks {
+			if block.Language == code.LiveLanguage {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// refreshLive executes the live blocks of the current slide whose refresh
+// interval elapsed. Live blocks on other folien are paused.
+func (m *Model) refreshLive() tea.Cmd {
+	if !m.AllowExecution || !m.hasLive || !m.ready || len(m.Slides) == 0 {
+		return nil
+	}
+	blocks, err := code.Parse(m.currentSlide())
+	if err != nil {
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	dir := m.workspace()
+	for i, block := range blocks {
+		if block.Language != code.LiveLanguage {
+			continue
+		}
+		key := liveKey{page: m.Page, block: i}
+		state, ok := m.live[key]
+		if !ok {
+			state = &liveBlock{}
+			m.live[key] = state
+		}
+		if state.running || time.Since(state.lastRun) < code.RefreshInterval(block) {
+			continue
+		}
+		state.running = true
+		command := block.Code
+		cmds = append(cmds, func() tea.Msg {
+			return liveOutputMsg{key: key, out: code.RunShell(command, dir)}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *Model) handleLiveOutput(msg liveOutputMsg) {
+	state, ok := m.live[msg.key]
+	if !ok {
+		return
+	}
+	state.running = false
+	state.lastRun = time.Now()
+	state.out = m.redactor.Redact(msg.out)
+	if msg.key.page == m.Page {
+		m.updateViewportContent()
+	}
+}
+
+// renderLive replaces the live blocks of the slide with their latest output.
+// With format=markdown the output is rendered as markdown, otherwise as code.
+func (m *Model) renderLive(slide string) string {
+	return code.ReplaceBlocks(slide, func(i int, block code.Block) (string, bool) {
+		if block.Language != code.LiveLanguage {
+			return "", false
+		}
+		if !m.AllowExecution {
+			return code.Fence("", "Execution is disabled"), true
+		}
+		state, ok := m.live[liveKey{page: m.Page, block: i}]
+		if !ok || state.lastRun.IsZero() {
+			return code.Fence("", "Loading…"), true
+		}
+		if block.Attributes["format"] == "markdown" {
+			return "\n" + state.out + "\n", true
+		}
+		return code.Fence("", state.out), true
+	})
+}
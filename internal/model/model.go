@@ -5,7 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -18,6 +22,9 @@ import (
 
 	"github.com/c0rydoras/folien/internal/code"
 	"github.com/c0rydoras/folien/internal/meta"
+	"github.com/c0rydoras/folien/internal/redact"
+	"github.com/c0rydoras/folien/internal/remote"
+	"github.com/c0rydoras/folien/internal/terminal"
 	"github.com/c0rydoras/folien/styles"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -28,10 +35,6 @@ var (
 	tabSpaces = strings.Repeat(" ", 4)
 )
 
-const (
-	delimiter = "\n---\n"
-)
-
 type HideInternalError int
 
 const (
@@ -46,8 +49,10 @@ const (
 // Model represents the model of this presentation, which contains all the
 // state related to the current folien.
 type Model struct {
-	Slides   []string
-	Page     int
+	Slides []string
+	Page   int
+	// Step is the reveal step of the current slide, see parser.Pauses.
+	Step     int
 	Author   string
 	Date     string
 	Theme    glamour.TermRendererOption
@@ -60,24 +65,120 @@ type Model struct {
 	VirtualText  string
 	Search       navigation.Search
 	Preprocessor *preprocessor.Config
+	// Dependencies are the files the folien file depends on (e.g. included
+	// files), they are watched for changes as well.
+	Dependencies []string
+	// Session executes the code blocks and caches the results of named
+	// blocks while the presentation is running.
+	Session *code.Session
+	// Redact configures which secrets are masked, it is merged with the
+	// configuration from the frontmatter when loading.
+	Redact   redact.Config
+	redactor *redact.Redactor
+	// redacted contains the redacted folien for searching
+	redacted []string
 	// TODO: move into some proper config struct
 	HideInternalErrors HideInternalError
 	AllowExecution     bool
-	ready              bool
+	// Served is set for the presentations of SSH sessions, viewers must not
+	// get control over the host, so terminal blocks and editing code blocks
+	// are disabled.
+	Served bool
+	ready  bool
+	// revealHidden shows the hidden lines of the current slide dimmed
+	revealHidden bool
+	// demos contains the state of the demo blocks
+	demos map[demoKey]*code.Demo
+	// terminals contains the running terminals by page
+	terminals       map[int]*terminal.Terminal
+	terminalFocused bool
+	// edits contains the edited code blocks by page and block index, they are
+	// kept for the session only
+	edits  map[int]map[int]string
+	editor *editor
+	// live contains the state of the live blocks
+	live map[liveKey]*liveBlock
+	// hasLive is whether the presentation contains live blocks, the tick
+	// refreshing them only runs if needed
+	hasLive bool
+	// tickGeneration identifies the running tick, see restartTick
+	tickGeneration int
+	// Layout and SplitRatio configure where the output of executed code blocks
+	// is displayed, if unset the frontmatter is used.
+	Layout        Layout
+	SplitRatio    float64
+	layout        Layout
+	splitRatio    float64
+	output        viewport.Model
+	outputFocused bool
+	width, height int
+	// toc is the interactive table of contents, it is nil while closed
+	toc *toc
+	// numbered is whether the preprocessor numbered the headings, on the
+	// command line or in the frontmatter.
+	numbered bool
+	// Renderers are commands rendering code blocks by their language, the
+	// renderers of the frontmatter are added to them.
+	Renderers     map[string]string
+	metaRenderers map[string]string
+	// renders caches the output of the renderers
+	renders *renderCache
+	// Incremental reveals the items of lists one at a time, it is enabled by
+	// the frontmatter as well.
+	Incremental     bool
+	metaIncremental bool
+	// notes contains the speaker notes by page, they are removed from the
+	// folien when loading.
+	notes []string
+	// Remote keeps the position in sync with the other programs presenting
+	// the file, e.g. the audience and the presenter view.
+	Remote *remote.Peer
+	// Presenter displays the notes of the current slide, a preview of the
+	// next one and a timer instead of the slide.
+	Presenter bool
+	started   time.Time
+	// Profile selects the folien marked for this profile, see parser.Select.
+	Profile string
+	// appendix is the index of the first slide of the appendix, the appendix
+	// is not counted by the paging.
+	appendix int
 }
 
 type fileWatchMsg struct{}
 
-var fileInfo os.FileInfo
+// modTimes contains the modification times of the watched files.
+var modTimes map[string]time.Time
 
-// Init initializes the model and begins watching the folien file for changes
-// if it exists.
+// Init initializes the model and begins watching the folien file and the
+// files it depends on for changes if it exists.
 func (m Model) Init() tea.Cmd {
+	var cmds []tea.Cmd
+	if m.ticking() {
+		cmds = append(cmds, liveTickCmd(m.tickGeneration))
+	}
+	if m.Remote != nil {
+		cmds = append(cmds, m.receivePosition())
+	}
 	if m.FileName == "" {
-		return nil
+		return tea.Batch(append(cmds, m.renders.start())...)
+	}
+	modTimes = readModTimes(m.watchedFiles())
+	return tea.Batch(append(cmds, fileWatchCmd(), m.renders.start())...)
+}
+
+// watchedFiles returns the folien file and the files it depends on.
+func (m *Model) watchedFiles() []string {
+	return append([]string{m.FileName}, m.Dependencies...)
+}
+
+func readModTimes(files []string) map[string]time.Time {
+	times := map[string]time.Time{}
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			times[file] = info.ModTime()
+		}
 	}
-	fileInfo, _ = os.Stat(m.FileName)
-	return fileWatchCmd()
+	return times
 }
 
 func fileWatchCmd() tea.Cmd {
@@ -107,14 +208,86 @@ func (m *Model) Load() error {
 	if exists {
 		content = parser.RemoveFrontMatter(content)
 	}
-	folien := strings.Split(content, delimiter)
 
-	m.Slides = folien
+	content, m.Dependencies, err = preprocessor.ResolveIncludes(content, m.FileName)
+	if err != nil {
+		return err
+	}
+	content, snippets, err := preprocessor.ImportSnippets(content, preprocessor.BaseDir(m.FileName))
+	if err != nil {
+		return err
+	}
+	content, tables, err := preprocessor.ImportTables(content, preprocessor.BaseDir(m.FileName))
+	if err != nil {
+		return err
+	}
+	m.Dependencies = slices.Concat(m.Dependencies, snippets, tables)
+	if bibliography := metaData.Bibliography; bibliography != "" {
+		if !filepath.IsAbs(bibliography) {
+			bibliography = filepath.Join(preprocessor.BaseDir(m.FileName), bibliography)
+		}
+		m.Dependencies = append(m.Dependencies, bibliography)
+	}
+	folien, err := parser.Split(content, metaData.Separator)
+	if err != nil {
+		return err
+	}
+	folien = parser.Select(folien, m.Profile)
+	if len(folien) == 0 {
+		return fmt.Errorf("no folien in profile %q", m.Profile)
+	}
 
 	if m.Preprocessor != nil {
-		m.Slides = m.Preprocessor.Process(folien)
+		config := m.Preprocessor.WithMeta(metaData)
+		folien, err = config.Process(folien)
+		if err != nil {
+			return err
+		}
+		m.numbered = config.Numbering()
+	}
+
+	previous := m.Slides
+	m.Slides = folien
+
+	m.redactor, err = redact.New(m.Redact.Merge(metaData.Redact))
+	if err != nil {
+		return err
+	}
+	m.appendix, m.Slides = parser.Appendix(m.Slides)
+	m.notes = make([]string, len(m.Slides))
+	for i, slide := range m.Slides {
+		m.notes[i], m.Slides[i] = parser.Notes(slide)
+	}
+	// the folien are only redacted when they are displayed or searched, the
+	// executed code keeps the secrets
+	m.redacted = m.redactor.RedactAll(slices.Clone(m.Slides))
+	m.hasLive = containsLive(m.Slides)
+
+	// terminals and edits survive reloading the presentation
+	terminals, edits, editor := m.terminals, m.edits, m.editor
+	m.ResetState()
+	if terminals != nil {
+		m.terminals, m.edits, m.editor = terminals, edits, editor
+		m.closeMovedTerminals(previous)
+		m.dropChangedEdits(previous)
+	}
+
+	if m.layout == "" {
+		m.layout, err = ParseLayout(metaData.Layout.Mode)
+		if err != nil {
+			return err
+		}
+		if m.Layout != "" {
+			m.layout = m.Layout
+		}
+		m.splitRatio = metaData.Layout.Ratio
+		if m.SplitRatio != 0 {
+			m.splitRatio = m.SplitRatio
+		}
 	}
 
+	m.metaRenderers = metaData.Renderers
+	m.metaIncremental = metaData.Incremental
 	m.Author = metaData.Author
 	m.Date = metaData.Date
 	m.Paging = metaData.Paging
@@ -127,30 +300,62 @@ func (m *Model) Load() error {
 	return nil
 }
 
-// Update updates the presentation model.
+// ResetState discards the state of the running presentation, i.e. the results
+// of executed blocks, demos, terminals and edits, so that copies of the model can be
+// used by independent programs.
+func (m *Model) ResetState() {
+	m.Session = code.NewSession(m.Slides)
+	m.demos = map[demoKey]*code.Demo{}
+	m.terminals = map[int]*terminal.Terminal{}
+	m.edits = map[int]map[int]string{}
+	m.editor = nil
+	m.live = map[liveKey]*liveBlock{}
+	m.renders = newRenderCache()
+}
+
+// Update updates the presentation model and starts the renderers of the
+// blocks displayed as a result.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	return next, tea.Batch(cmd, m.renders.start())
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var (
 		cmd  tea.Cmd
 		cmds []tea.Cmd
 	)
 
+	if m.Presenter && m.started.IsZero() {
+		m.started = time.Now()
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		footerHeight := 3
-		if !m.ready {
-			m.viewport = viewport.New(msg.Width, msg.Height-footerHeight)
-			m.viewport.YPosition = 0
-			m.ready = true
-			m.updateViewportContent()
-		} else {
-			m.viewport.Width = msg.Width
-			m.viewport.Height = msg.Height - footerHeight
-		}
+		m.resize(msg.Width, msg.Height)
 		return m, nil
 
+	case positionMsg:
+		m.receive(msg)
+		return m, m.receivePosition()
+
 	case tea.KeyMsg:
 		keyPress := msg.String()
 
+		if m.terminalFocused {
+			m.handleTerminalKey(msg)
+			return m, nil
+		}
+
+		if m.editor != nil {
+			return m, m.handleEditorKey(msg)
+		}
+
+		if m.toc != nil {
+			m.handleTOCKey(keyPress)
+			return m, nil
+		}
+
 		if m.Search.Active {
 			switch msg.Type {
 			case tea.KeyEnter:
@@ -184,11 +389,37 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Go to next occurrence
 			m.Search.Execute(&m)
 		case "ctrl+e":
+			// Advance demo
+			if key, d := m.demo(); d != nil {
+				if !m.AllowExecution {
+					m.VirtualText = "\nExecution is disabled"
+					m.updateViewportContent()
+					return m, nil
+				}
+				return m, m.advanceDemo(key, d)
+			}
 			// Run code blocks
-			blocks, err := code.Parse(m.Slides[m.Page])
-			if err != nil {
-				// We couldn't parse the code block on the screen
-				m.VirtualText = "\n" + err.Error()
+			return m, m.executeBlocks()
+		case "e":
+			// Edit code block
+			if m.Served {
+				m.VirtualText = "\nEditing is disabled when serving"
+				m.updateViewportContent()
+				return m, nil
+			}
+			return m, m.openEditor()
+		case "E":
+			// Reset edited code blocks
+			m.resetEdits()
+			return m, nil
+		case "i":
+			// Focus terminal
+			block, ok := m.terminalBlock()
+			if !ok {
+				return m, nil
+			}
+			if m.Served {
+				m.VirtualText = "\nTerminals are disabled when serving"
 				m.updateViewportContent()
 				return m, nil
 			}
@@ -197,31 +428,38 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.updateViewportContent()
 				return m, nil
 			}
-			var outs []string
-			for i, block := range blocks {
-				res := code.Execute(block)
-				if res.ExitCode == code.ExitCodeInternalError {
-					if m.HideInternalErrors == All {
-						continue
-					}
-					if m.HideInternalErrors == AllButLast && i != len(blocks)-1 {
-						continue
-					}
-				}
-				outs = append(outs, res.Out)
+			return m, m.focusTerminal(block)
+		case "t":
+			// Open table of contents
+			m.openTOC()
+			return m, nil
+		case "tab":
+			// Switch focus between slide and output
+			if m.split() {
+				m.outputFocused = !m.outputFocused
 			}
-			m.VirtualText = strings.Join(outs, "\n")
+			return m, nil
+		case "L":
+			// Cycle through layouts
+			m.layout = m.layout.next()
+			m.resize(m.width, m.height)
+			return m, nil
+		case "H":
+			// Toggle hidden lines
+			m.revealHidden = !m.revealHidden
 			m.updateViewportContent()
+			return m, nil
 		case "y":
-			blocks, err := code.Parse(m.Slides[m.Page])
+			blocks, err := code.Parse(m.currentSlide())
 			if err != nil {
 				return m, nil
 			}
 			for _, b := range blocks {
-				_ = clipboard.WriteAll(b.Code)
+				_ = clipboard.WriteAll(m.redactor.Redact(b.Code))
 			}
 			return m, nil
 		case "ctrl+c", "q":
+			m.CloseTerminals()
 			return m, tea.Quit
 		default:
 			if m.shouldHandleViewportNavigation(keyPress) {
@@ -234,13 +472,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.buffer = ""
 					}
 
+					vp := m.focusedViewport()
 					for i := 0; i < repeat; i++ {
-						m.viewport, cmd = m.viewport.Update(msg)
+						*vp, cmd = vp.Update(msg)
 						cmds = append(cmds, cmd)
 					}
 					return m, tea.Batch(cmds...)
 				} else {
-					m.viewport, cmd = m.viewport.Update(msg)
+					vp := m.focusedViewport()
+					*vp, cmd = vp.Update(msg)
 					cmds = append(cmds, cmd)
 					return m, tea.Batch(cmds...)
 				}
@@ -250,26 +490,62 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				Buffer:      m.buffer,
 				Page:        m.Page,
 				TotalSlides: len(m.Slides),
+				Step:        m.Step,
+				Steps:       m.steps(),
 			}, keyPress)
 			m.buffer = newState.Buffer
 			if newState.Page != m.Page {
-				m.SetPage(newState.Page)
-				m.updateViewportContent()
+				m.setPosition(newState.Page, newState.Step)
 				m.viewport.GotoTop()
+			} else if newState.Step != m.Step {
+				m.setPosition(newState.Page, newState.Step)
 			}
 		}
 
+	case demoTypeMsg:
+		return m, m.handleDemoType(msg)
+
+	case demoOutputMsg:
+		m.handleDemoOutput(msg)
+		return m, nil
+
+	case liveTickMsg:
+		return m, m.handleLiveTick(msg)
+
+	case liveOutputMsg:
+		m.handleLiveOutput(msg)
+		return m, nil
+
+	case executionMsg:
+		m.handleExecution(msg)
+		return m, nil
+
+	case renderedMsg:
+		m.handleRendered(msg)
+		return m, nil
+
+	case terminal.UpdateMsg:
+		return m, m.handleTerminalUpdate(msg)
+
+	case terminal.ExitMsg:
+		m.handleTerminalExit(msg)
+		return m, nil
+
 	case fileWatchMsg:
-		newFileInfo, err := os.Stat(m.FileName)
-		if err == nil && newFileInfo.ModTime() != fileInfo.ModTime() {
-			fileInfo = newFileInfo
+		newModTimes := readModTimes(m.watchedFiles())
+		if !maps.Equal(newModTimes, modTimes) {
+			wasTicking := m.ticking()
 			_ = m.Load()
+			cmds = append(cmds, m.restartTick(wasTicking))
+			// dependencies might have changed
+			modTimes = readModTimes(m.watchedFiles())
 			if m.Page >= len(m.Slides) {
 				m.Page = len(m.Slides) - 1
 			}
+			m.Step = min(m.Step, m.lastStep(m.Page))
 			m.updateViewportContent()
 		}
-		return m, fileWatchCmd()
+		return m, tea.Batch(append(cmds, fileWatchCmd())...)
 	}
 
 	if !m.Search.Active {
@@ -287,23 +563,92 @@ func (m Model) View() string {
 		return "\n  Initializing..."
 	}
 
-	slide := styles.Slide.Render(m.viewport.View())
+	slide := m.viewPanes()
+	if m.editor != nil {
+		slide = m.viewEditor()
+	}
+	if m.toc != nil {
+		slide = m.viewTOC()
+	}
 
 	var left string
 	if m.Search.Active {
 		// render search bar
 		left = m.Search.SearchTextInput.View()
+	} else if m.Presenter {
+		// render timer and clock
+		left = styles.Author.Render(m.elapsed()) + styles.Date.Render(time.Now().Format("15:04"))
 	} else {
 		// render author and date
 		left = styles.Author.Render(m.Author) + styles.Date.Render(m.Date)
+		if breadcrumb := preprocessor.Breadcrumb(m.redactor.Redact(m.Slides[m.Page])); breadcrumb != "" {
+			left += styles.Breadcrumb.Render(breadcrumb)
+		}
 	}
 
 	right := styles.Page.Render(m.paging())
-	status := styles.Status.Render(styles.JoinHorizontal(left, right, m.viewport.Width))
+	if m.Remote != nil && !m.Remote.Connected() {
+		// the other view is not running yet or the connection was lost
+		right = styles.Unsynced.Render("not in sync") + right
+	}
+	status := styles.Status.Render(styles.JoinHorizontal(left, right, m.width))
 
 	return fmt.Sprintf("%s\n%s", slide, status)
 }
 
+// executionMsg contains the output of the code blocks executed on a page.
+type executionMsg struct {
+	page int
+	out  string
+}
+
+// executeBlocks executes the code blocks of the current slide in the
+// background, their output is displayed once all of them finished.
+func (m *Model) executeBlocks() tea.Cmd {
+	blocks, err := code.Parse(m.currentSlide())
+	if err != nil {
+		// We couldn't parse the code block on the screen
+		m.VirtualText = "\n" + err.Error()
+		m.updateViewportContent()
+		return nil
+	}
+	if !m.AllowExecution {
+		m.VirtualText = "\nExecution is disabled"
+		m.updateViewportContent()
+		return nil
+	}
+	m.VirtualText = "\nRunning…"
+	m.updateViewportContent()
+
+	page, session, hide := m.Page, m.Session, m.HideInternalErrors
+	return func() tea.Msg {
+		var outs []string
+		for i, block := range blocks {
+			res := session.Execute(block)
+			if res.ExitCode == code.ExitCodeInternalError {
+				if hide == All {
+					continue
+				}
+				if hide == AllButLast && i != len(blocks)-1 {
+					continue
+				}
+			}
+			outs = append(outs, res.Out)
+		}
+		return executionMsg{page: page, out: strings.Join(outs, "\n")}
+	}
+}
+
+// handleExecution displays the output of the executed code blocks, unless the
+// page was left in the meantime.
+func (m *Model) handleExecution(msg executionMsg) {
+	if msg.page != m.Page {
+		return
+	}
+	m.VirtualText = m.redactor.Redact(msg.out)
+	m.updateViewportContent()
+}
+
 func (m *Model) shouldHandleViewportNavigation(keyPress string) bool {
 	scrollKeys := map[string]bool{
 		"up":     true,
@@ -331,11 +676,28 @@ func (m *Model) updateViewportContent() {
 	}
 
 	r, _ := glamour.NewTermRenderer(m.Theme, glamour.WithWordWrap(m.viewport.Width))
-	slide := m.Slides[m.Page]
-	slide = code.HideComments(slide)
+	if m.Presenter {
+		m.viewport.SetContent("\n\n" + m.presenterContent(r))
+		return
+	}
+
+	// the slide is revealed before the blocks are substituted, so that the
+	// output of blocks cannot add steps
+	slide := m.reveal(m.currentSlide())
+	slide = m.renderDemos(slide)
+	slide = m.renderLive(slide)
+	slide = m.renderTerminals(slide)
+	slide = m.renderBlocks(slide)
+	slide = code.HideLines(slide, m.revealHidden)
+	slide = m.redactor.Redact(slide)
 	slide, err := r.Render(slide)
 	slide = strings.ReplaceAll(slide, "\t", tabSpaces)
-	slide += m.VirtualText
+	if m.revealHidden {
+		slide = dimHiddenLines(slide)
+	}
+	if !m.split() {
+		slide += m.VirtualText
+	}
 	if err != nil {
 		slide = fmt.Sprintf("Error: Could not render markdown! (%v)", err)
 	}
@@ -343,6 +705,32 @@ func (m *Model) updateViewportContent() {
 	slide = "\n\n" + slide
 
 	m.viewport.SetContent(slide)
+	m.updateOutputContent()
+}
+
+// faint is the escape sequence of faint text, the style of revealed hidden
+// lines.
+const faint = "\x1b[2m"
+
+// dimHiddenLines renders the revealed hidden lines of a rendered slide faint,
+// keeping their syntax highlighting.
+func dimHiddenLines(slide string) string {
+	lines := strings.Split(slide, "\n")
+	for i, line := range lines {
+		if strings.Contains(line, code.HiddenLineMarker) {
+			lines[i] = dim(strings.ReplaceAll(line, code.HiddenLineMarker, ""))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sgrRegexp matches the escape sequences setting the style of text.
+var sgrRegexp = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// dim renders the styled line faint, the faint attribute is set again after
+// each escape sequence of the line as they might reset it.
+func dim(line string) string {
+	return faint + sgrRegexp.ReplaceAllString(line, "${0}"+faint) + "\x1b[0m"
 }
 
 func (m *Model) bufferIsNumeric() bool {
@@ -357,15 +745,39 @@ func (m *Model) bufferIsNumeric() bool {
 	return true
 }
 
+// paging formats the page, the total number of folien, the step and the
+// number of steps of the current slide with the paging of the frontmatter.
+// The step is appended to formats without it on folien with pauses. The
+// folien of the appendix are not counted, they are numbered separately.
 func (m *Model) paging() string {
+	page, total := m.Page+1, min(m.appendix, len(m.Slides))
+	prefix := ""
+	if m.Page >= m.appendix {
+		page, total = m.Page-m.appendix+1, len(m.Slides)-m.appendix
+		prefix = "Appendix · "
+	}
+
+	step, steps := m.Step+1, m.lastStep(m.Page)+1
+	switch strings.Count(m.Paging, "%d") {
+	case 4:
+		return prefix + fmt.Sprintf(m.Paging, page, total, step, steps)
+	case 3:
+		return prefix + fmt.Sprintf(m.Paging, page, total, step)
+	}
+
+	var paging string
 	switch strings.Count(m.Paging, "%d") {
 	case 2:
-		return fmt.Sprintf(m.Paging, m.Page+1, len(m.Slides))
+		paging = fmt.Sprintf(m.Paging, page, total)
 	case 1:
-		return fmt.Sprintf(m.Paging, m.Page+1)
+		paging = fmt.Sprintf(m.Paging, page)
 	default:
-		return m.Paging
+		paging = m.Paging
+	}
+	if steps > 1 {
+		paging += fmt.Sprintf(" · %d/%d", step, steps)
 	}
+	return prefix + paging
 }
 
 func readStdin() (string, error) {
@@ -400,18 +812,40 @@ func (m *Model) CurrentPage() int {
 	return m.Page
 }
 
-// SetPage sets which page the presentation should render.
+// SetPage sets which page the presentation should render, the page is fully
+// revealed.
 func (m *Model) SetPage(page int) {
-	if m.Page == page {
+	m.setPosition(page, m.lastStep(page))
+}
+
+// setPosition sets the page and the reveal step of the page to render and
+// sends them to the other programs presenting the file.
+func (m *Model) setPosition(page, step int) {
+	if m.Page == page && m.Step == step {
 		return
 	}
+	m.showPosition(page, step)
+	if m.Remote != nil {
+		m.Remote.Send(remote.Position{Page: page, Step: step})
+	}
+}
 
-	m.VirtualText = ""
-	m.Page = page
+// showPosition sets the page and the reveal step of the page to render.
+func (m *Model) showPosition(page, step int) {
+	if m.Page != page {
+		if m.editor != nil {
+			m.saveEdit()
+		}
+		m.VirtualText = ""
+		m.revealHidden = false
+		m.terminalFocused = false
+	}
+	m.Page, m.Step = page, step
 	m.updateViewportContent()
 }
 
-// Pages returns all the folien in the presentation.
+// Pages returns all the folien in the presentation with their secrets
+// redacted.
 func (m *Model) Pages() []string {
-	return m.Slides
+	return m.redacted
 }
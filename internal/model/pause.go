@@ -0,0 +1,30 @@
+package model
+
+import "github.com/c0rydoras/folien/pkg/parser"
+
+// incremental returns whether the items of lists are revealed one at a time.
+func (m *Model) incremental() bool {
+	return m.Incremental || m.metaIncremental
+}
+
+// steps returns the number of reveal steps of each slide.
+func (m *Model) steps() []int {
+	steps := make([]int, len(m.Slides))
+	for i, slide := range m.Slides {
+		steps[i] = len(parser.Pauses(slide, m.incremental())) + 1
+	}
+	return steps
+}
+
+// lastStep returns the step at which the slide is fully revealed.
+func (m *Model) lastStep(page int) int {
+	if page < 0 || page >= len(m.Slides) {
+		return 0
+	}
+	return len(parser.Pauses(m.Slides[page], m.incremental()))
+}
+
+// reveal returns the part of the slide shown at the current step.
+func (m *Model) reveal(slide string) string {
+	return parser.Reveal(slide, m.Step, m.incremental())
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/c0rydoras/folien/internal/code"
+	"github.com/c0rydoras/folien/internal/remote"
+	"github.com/c0rydoras/folien/pkg/parser"
+	"github.com/c0rydoras/folien/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+)
+
+// positionMsg is a position received from another program presenting the
+// file.
+type positionMsg remote.Position
+
+// receivePosition waits for the next position sent by another program.
+func (m *Model) receivePosition() tea.Cmd {
+	positions := m.Remote.Positions()
+	return func() tea.Msg {
+		return positionMsg(<-positions)
+	}
+}
+
+// receive shows the position received from another program, it is not sent
+// back.
+func (m *Model) receive(msg positionMsg) {
+	if len(m.Slides) == 0 {
+		return
+	}
+	page := min(max(msg.Page, 0), len(m.Slides)-1)
+	step := min(max(msg.Step, 0), m.lastStep(page))
+	if page == m.Page && step == m.Step {
+		return
+	}
+	if page != m.Page {
+		m.viewport.GotoTop()
+	}
+	m.showPosition(page, step)
+}
+
+// presenterContent renders the notes of the current slide and a preview of
+// the next step, or of the next slide on the last step.
+func (m *Model) presenterContent(r *glamour.TermRenderer) string {
+	notes := "*No notes*"
+	if m.Page < len(m.notes) && m.notes[m.Page] != "" {
+		notes = m.notes[m.Page]
+	}
+
+	label, next := "Next slide", "*End of presentation*"
+	switch {
+	case m.Step < m.lastStep(m.Page):
+		label, next = "Next step", parser.Reveal(m.Slides[m.Page], m.Step+1, m.incremental())
+	case m.Page+1 < len(m.Slides):
+		next = parser.Reveal(m.Slides[m.Page+1], 0, m.incremental())
+	}
+
+	var b strings.Builder
+	for _, section := range []struct{ label, content string }{
+		{"Notes", notes},
+		{label, code.HideLines(next, false)},
+	} {
+		content, err := r.Render(m.redactor.Redact(section.content))
+		if err != nil {
+			content = fmt.Sprintf("Error: Could not render markdown! (%v)", err)
+		}
+		b.WriteString(styles.Label.Render(strings.ToUpper(section.label)) + "\n")
+		b.WriteString(strings.ReplaceAll(content, "\t", tabSpaces) + "\n")
+	}
+	return b.String()
+}
+
+// elapsed formats the time elapsed since the presenter view was started.
+func (m *Model) elapsed() string {
+	elapsed := time.Duration(0)
+	if !m.started.IsZero() {
+		elapsed = time.Since(m.started)
+	}
+	seconds := int(elapsed.Seconds())
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
@@ -0,0 +1,172 @@
+package model
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/c0rydoras/folien/internal/code"
+	"github.com/c0rydoras/folien/internal/mermaid"
+	"github.com/c0rydoras/folien/styles"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// blockMargins is the horizontal space the theme adds around code blocks,
+	// the margins of the document and of the code block on both sides.
+	blockMargins = 8
+	// maxRendered is the number of outputs kept in the render cache, the
+	// oldest outputs are dropped first.
+	maxRendered = 64
+)
+
+// renderKey identifies the output of a renderer. Outputs are cached per
+// width, as the width of the slide changes the output.
+type renderKey struct {
+	language, content string
+	width             int
+}
+
+// renderedMsg contains the output of a renderer.
+type renderedMsg struct {
+	key renderKey
+	out string
+}
+
+// renderJob is a block waiting to be rendered by a command.
+type renderJob struct {
+	key           renderKey
+	command       string
+	dir           string
+	width, height int
+}
+
+// renderCache caches the outputs of the renderers and collects the blocks
+// which need to be rendered. It is shared by the copies of the model, so that
+// the renders queued while updating the viewport are started by Update.
+type renderCache struct {
+	outputs map[renderKey]string
+	order   []renderKey
+	// pending contains the blocks being rendered, a block is rendered for one
+	// width at a time
+	pending map[renderKey]bool
+	queue   []renderJob
+}
+
+func newRenderCache() *renderCache {
+	return &renderCache{outputs: map[renderKey]string{}, pending: map[renderKey]bool{}}
+}
+
+func (c *renderCache) get(key renderKey) (string, bool) {
+	out, ok := c.outputs[key]
+	return out, ok
+}
+
+func (c *renderCache) put(key renderKey, out string) {
+	if _, ok := c.outputs[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.outputs[key] = out
+	for len(c.order) > maxRendered {
+		delete(c.outputs, c.order[0])
+		c.order = slices.Delete(c.order, 0, 1)
+	}
+}
+
+// enqueue queues the block to be rendered unless the block is already being
+// rendered.
+func (c *renderCache) enqueue(job renderJob) {
+	block := renderKey{language: job.key.language, content: job.key.content}
+	if c.pending[block] {
+		return
+	}
+	c.pending[block] = true
+	c.queue = append(c.queue, job)
+}
+
+// start returns the command running the queued renders.
+func (c *renderCache) start() tea.Cmd {
+	if c == nil || len(c.queue) == 0 {
+		return nil
+	}
+	cmds := make([]tea.Cmd, 0, len(c.queue))
+	for _, job := range c.queue {
+		cmds = append(cmds, func() tea.Msg {
+			out, err := code.Render(job.command, job.key.content, job.dir, job.width, job.height)
+			if err != nil {
+				out = "Error: " + err.Error()
+			}
+			return renderedMsg{key: job.key, out: out}
+		})
+	}
+	c.queue = nil
+	return tea.Batch(cmds...)
+}
+
+// handleRendered caches the output of a renderer and displays it.
+func (m *Model) handleRendered(msg renderedMsg) {
+	delete(m.renders.pending, renderKey{language: msg.key.language, content: msg.key.content})
+	m.renders.put(msg.key, m.redactor.Redact(msg.out))
+	m.updateViewportContent()
+}
+
+// renderers returns the commands rendering code blocks by their language,
+// the renderers from the command line take precedence over the frontmatter.
+func (m *Model) renderers() map[string]string {
+	renderers := maps.Clone(m.metaRenderers)
+	if renderers == nil {
+		renderers = map[string]string{}
+	}
+	maps.Copy(renderers, m.Renderers)
+	return renderers
+}
+
+// renderBlocks replaces the code blocks which have a renderer with the output
+// of the renderer for the current width of the slide. The renderers run in the
+// background, a placeholder is shown until their output arrives. Mermaid
+// diagrams are drawn by the built-in renderer unless another renderer is
+// configured.
+func (m *Model) renderBlocks(slide string) string {
+	renderers := m.renderers()
+
+	width := max(1, m.viewport.Width-styles.Slide.GetHorizontalFrameSize()-blockMargins)
+	height := max(1, m.viewport.Height-styles.Slide.GetVerticalFrameSize())
+	return code.ReplaceBlocks(slide, func(_ int, block code.Block) (string, bool) {
+		command, ok := renderers[block.Language]
+		if !ok {
+			if block.Language != mermaid.Language {
+				return "", false
+			}
+			return m.renderMermaid(block, width)
+		}
+		// without execution the source of the block is shown
+		if !m.AllowExecution {
+			return "", false
+		}
+
+		key := renderKey{language: block.Language, content: block.Code, width: width}
+		out, ok := m.renders.get(key)
+		if !ok {
+			m.renders.enqueue(renderJob{key: key, command: command, dir: m.workspace(), width: width, height: height})
+			return code.Fence("", "Rendering…"), true
+		}
+		return code.Fence("", out), true
+	})
+}
+
+// renderMermaid draws the Mermaid diagram, diagrams which are not supported
+// are shown as source.
+func (m *Model) renderMermaid(block code.Block, width int) (string, bool) {
+	key := renderKey{language: block.Language, content: block.Code, width: width}
+	out, ok := m.renders.get(key)
+	if !ok {
+		var err error
+		if out, err = mermaid.Render(block.Code, width); err != nil {
+			out = ""
+		}
+		m.renders.put(key, out)
+	}
+	if out == "" {
+		return "", false
+	}
+	return code.Fence("", out), true
+}
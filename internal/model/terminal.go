@@ -0,0 +1,199 @@
+package model
+
+import (
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/c0rydoras/folien/internal/code"
+	"github.com/c0rydoras/folien/internal/terminal"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// terminalDetachKey returns the focus from the terminal to the
+	// presentation.
+	terminalDetachKey = "ctrl+]"
+	// defaultTerminalHeight is the height of a terminal block without a
+	// height attribute.
+	defaultTerminalHeight = 10
+	// terminalMargin is the horizontal space taken by the margins of the
+	// slide and the code block around the terminal.
+	terminalMargin = 12
+	// terminalVerticalMargin is the vertical space taken by the padding of
+	// the slide, the fences of the code block and the detach hint.
+	terminalVerticalMargin = 8
+)
+
+// terminalBlock returns the first terminal block of the current slide.
+func (m *Model) terminalBlock() (code.Block, bool) {
+	return terminalBlockOf(m.Slides[m.Page])
+}
+
+// terminalBlockOf returns the first terminal block of the slide.
+func terminalBlockOf(slide string) (code.Block, bool) {
+	blocks, err := code.Parse(slide)
+	if err != nil {
+		return code.Block{}, false
+	}
+	for _, block := range blocks {
+		if block.Language == terminal.Language {
+			return block, true
+		}
+	}
+	return code.Block{}, false
+}
+
+// focusTerminal focuses the terminal of the current slide, starting its shell
+// if it is not running yet.
+func (m *Model) focusTerminal(block code.Block) tea.Cmd {
+	if _, ok := m.terminals[m.Page]; ok {
+		m.terminalFocused = true
+		m.updateViewportContent()
+		return nil
+	}
+
+	t, err := terminal.Start(m.workspace(), m.terminalWidth(), m.terminalHeight(block))
+	if err != nil {
+		m.VirtualText = "\nError: could not start terminal: " + err.Error()
+		m.updateViewportContent()
+		return nil
+	}
+	if block.Code != "" {
+		_ = t.Write([]byte(block.Code))
+	}
+	m.terminals[m.Page] = t
+	m.terminalFocused = true
+	m.updateViewportContent()
+	return t.Wait(m.Page)
+}
+
+// handleTerminalKey passes the key press to the focused terminal.
+func (m *Model) handleTerminalKey(msg tea.KeyMsg) {
+	if msg.String() == terminalDetachKey {
+		m.terminalFocused = false
+		m.updateViewportContent()
+		return
+	}
+	t, ok := m.terminals[m.Page]
+	if !ok {
+		m.terminalFocused = false
+		return
+	}
+	_ = t.Write(terminal.KeyBytes(msg))
+}
+
+func (m *Model) handleTerminalUpdate(msg terminal.UpdateMsg) tea.Cmd {
+	t, ok := m.terminals[msg.ID]
+	if !ok {
+		return nil
+	}
+	if msg.ID == m.Page {
+		m.updateViewportContent()
+	}
+	return t.Wait(msg.ID)
+}
+
+func (m *Model) handleTerminalExit(msg terminal.ExitMsg) {
+	if t, ok := m.terminals[msg.ID]; ok {
+		_ = t.Close()
+		delete(m.terminals, msg.ID)
+	}
+	if msg.ID == m.Page {
+		m.terminalFocused = false
+		m.updateViewportContent()
+	}
+}
+
+// CloseTerminals stops the shells of all terminals, e.g. when the
+// presentation quits or its SSH session ends.
+func (m *Model) CloseTerminals() {
+	for page, t := range m.terminals {
+		_ = t.Close()
+		delete(m.terminals, page)
+	}
+	m.terminalFocused = false
+}
+
+// closeMovedTerminals closes the terminals whose block changed or may have
+// moved to another slide when the presentation was reloaded, the terminals
+// are kept by page.
+func (m *Model) closeMovedTerminals(previous []string) {
+	for page, t := range m.terminals {
+		if len(previous) == len(m.Slides) && page < len(m.Slides) && sameTerminalBlock(previous[page], m.Slides[page]) {
+			continue
+		}
+		_ = t.Close()
+		delete(m.terminals, page)
+		if page == m.Page {
+			m.terminalFocused = false
+		}
+	}
+}
+
+func sameTerminalBlock(a, b string) bool {
+	blockA, okA := terminalBlockOf(a)
+	blockB, okB := terminalBlockOf(b)
+	return okA && okB && blockA.Code == blockB.Code && maps.Equal(blockA.Attributes, blockB.Attributes)
+}
+
+// resizeTerminals adapts the size of all terminals to the viewport.
+func (m *Model) resizeTerminals() {
+	for page, t := range m.terminals {
+		height := m.terminalHeight(code.Block{})
+		if blocks, err := code.Parse(m.Slides[min(page, len(m.Slides)-1)]); err == nil {
+			for _, block := range blocks {
+				if block.Language == terminal.Language {
+					height = m.terminalHeight(block)
+					break
+				}
+			}
+		}
+		t.Resize(m.terminalWidth(), height)
+	}
+}
+
+// renderTerminals replaces the first terminal block of the slide with the
+// screen of its terminal.
+func (m *Model) renderTerminals(slide string) string {
+	rendered := false
+	return code.ReplaceBlocks(slide, func(_ int, block code.Block) (string, bool) {
+		if block.Language != terminal.Language || rendered {
+			return "", false
+		}
+		rendered = true
+
+		t, ok := m.terminals[m.Page]
+		if !ok {
+			hint := "Press i to start a shell"
+			switch {
+			case m.Served:
+				hint = "Terminals are disabled when serving"
+			case !m.AllowExecution:
+				hint = "Execution is disabled"
+			}
+			lines := make([]string, m.terminalHeight(block))
+			lines[0] = hint
+			return code.Fence("", strings.Join(lines, "\n")), true
+		}
+		view := t.View(m.terminalFocused)
+		if m.terminalFocused {
+			view += "\n\n" + terminalDetachKey + " to detach"
+		}
+		return code.Fence("", m.redactor.Redact(view)), true
+	})
+}
+
+func (m *Model) terminalWidth() int {
+	return max(20, m.viewport.Width-terminalMargin)
+}
+
+// terminalHeight returns the height given by the height attribute of the
+// block, at most the height of the viewport.
+func (m *Model) terminalHeight(block code.Block) int {
+	height, err := strconv.Atoi(block.Attributes["height"])
+	if err != nil || height < 1 {
+		height = defaultTerminalHeight
+	}
+	return min(height, max(3, m.viewport.Height-terminalVerticalMargin))
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/c0rydoras/folien/internal/preprocessor"
+	"github.com/c0rydoras/folien/styles"
+)
+
+const (
+	// tocHelp is displayed above the table of contents.
+	tocHelp = "j/k: move · enter: jump · esc: close"
+	// tocDepth is the deepest heading level listed in the table of contents
+	// if the preprocessor does not configure it.
+	tocDepth = 3
+)
+
+// toc is the interactive table of contents.
+type toc struct {
+	entries []preprocessor.TOCEntry
+	cursor  int
+}
+
+// openTOC opens the table of contents with the cursor on the current slide.
+func (m *Model) openTOC() {
+	depth := tocDepth
+	if m.Preprocessor != nil && m.Preprocessor.TOC.Depth > 0 {
+		depth = m.Preprocessor.TOC.Depth
+	}
+	entries := preprocessor.CollectTOC(m.Slides, depth)
+	if len(entries) == 0 {
+		return
+	}
+	cursor := 0
+	for i := range entries {
+		if entries[i].Slide <= m.Page {
+			cursor = i
+		}
+	}
+	m.toc = &toc{entries: entries, cursor: cursor}
+}
+
+// handleTOCKey moves the cursor of the table of contents or jumps to the
+// selected slide.
+func (m *Model) handleTOCKey(keyPress string) {
+	switch keyPress {
+	case "j", "down", "tab":
+		m.toc.cursor = min(m.toc.cursor+1, len(m.toc.entries)-1)
+	case "k", "up", "shift+tab":
+		m.toc.cursor = max(m.toc.cursor-1, 0)
+	case "g", "home":
+		m.toc.cursor = 0
+	case "G", "end":
+		m.toc.cursor = len(m.toc.entries) - 1
+	case "enter", " ", "l":
+		page := m.toc.entries[m.toc.cursor].Slide
+		m.toc = nil
+		if page != m.Page {
+			m.SetPage(page)
+			m.viewport.GotoTop()
+		}
+	case "esc", "t", "q", "ctrl+c":
+		m.toc = nil
+	}
+}
+
+func (m Model) viewTOC() string {
+	// numbered headings already contain their section
+	sectionNumbers := m.Preprocessor != nil && m.Preprocessor.TOC.SectionNumbers && !m.numbered
+	width := m.viewport.Width - styles.Slide.GetHorizontalFrameSize()
+	height := max(1, m.viewport.Height-styles.Slide.GetVerticalFrameSize()-2)
+
+	// scroll the list so that the cursor is visible
+	first := max(0, m.toc.cursor-height+1)
+	last := min(len(m.toc.entries), first+height)
+
+	lines := make([]string, 0, last-first)
+	for i, entry := range m.toc.entries[first:last] {
+		title := m.redactor.Redact(entry.Title)
+		if sectionNumbers {
+			title = entry.Section + " " + title
+		}
+		title = strings.Repeat("  ", entry.Depth) + title
+		number := fmt.Sprint(entry.Slide + 1)
+
+		if first+i == m.toc.cursor {
+			title = styles.Selected.Render("> " + title)
+		} else {
+			title = "  " + title
+		}
+		lines = append(lines, styles.JoinHorizontal(title, styles.Hidden.Render(number), width))
+	}
+
+	title := styles.Hidden.Render("Table of Contents · " + tocHelp)
+	return styles.Slide.Render(title + "\n\n" + strings.Join(lines, "\n"))
+}
@@ -4,16 +4,28 @@ import (
 	"strconv"
 )
 
-type repeatableFunc func(slide, totalSlides int) int
+type repeatableFunc func(position position, state State) position
+
+// position is a slide and the reveal step on it.
+type position struct {
+	page, step int
+}
 
 // State tracks the current buffer, page, and total number of folien
 type State struct {
 	Buffer      string
 	Page        int
 	TotalSlides int
+	// Step is the reveal step of the current slide, counted from 0.
+	Step int
+	// Steps are the numbers of reveal steps of the folien, folien without
+	// pauses (or missing from Steps) have a single step.
+	Steps []int
 }
 
 // Navigate receives the current State and keyPress, and returns the new State.
+// Moving forwards and backwards goes through the reveal steps of a slide
+// before moving to the next or previous slide, jumps show the first step.
 func Navigate(state State, keyPress string) State {
 	switch keyPress {
 	case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9":
@@ -23,24 +35,13 @@ func Navigate(state State, keyPress string) State {
 			newBuffer = state.Buffer + keyPress
 		}
 
-		return State{
-			Buffer:      newBuffer,
-			Page:        state.Page,
-			TotalSlides: state.TotalSlides,
-		}
+		return state.moveTo(newBuffer, position{state.Page, state.Step})
 	case "g":
 		switch state.Buffer {
 		case "g":
-			return State{
-				Page:        0,
-				TotalSlides: state.TotalSlides,
-			}
+			return state.moveTo("", position{})
 		default:
-			return State{
-				Buffer:      "g",
-				Page:        state.Page,
-				TotalSlides: state.TotalSlides,
-			}
+			return state.moveTo("g", position{state.Page, state.Step})
 		}
 	case "G":
 		targetSlide := state.TotalSlides - 1
@@ -48,40 +49,50 @@ func Navigate(state State, keyPress string) State {
 			targetSlide = navigateSlide(state.Buffer, state.TotalSlides)
 		}
 
-		return State{
-			Page:        targetSlide,
-			TotalSlides: state.TotalSlides,
-		}
+		return state.moveTo("", position{page: targetSlide})
 	case " ", "right", "l", "enter", "n":
-		return State{
-			Page:        navigateNext(state),
-			TotalSlides: state.TotalSlides,
-		}
+		return state.moveTo("", navigateNext(state))
 	case "left", "h", "p", "N":
-		return State{
-			Page:        navigatePrevious(state),
-			TotalSlides: state.TotalSlides,
-		}
+		return state.moveTo("", navigatePrevious(state))
 	default:
-		return State{
-			Page:        state.Page,
-			TotalSlides: state.TotalSlides,
-		}
+		return state.moveTo("", position{state.Page, state.Step})
+	}
+}
+
+// moveTo returns the state at the given position.
+func (s State) moveTo(buffer string, p position) State {
+	return State{
+		Buffer:      buffer,
+		Page:        p.page,
+		TotalSlides: s.TotalSlides,
+		Step:        p.step,
+		Steps:       s.Steps,
 	}
 }
 
+// steps returns the number of reveal steps of the slide.
+func (s State) steps(slide int) int {
+	if slide < 0 || slide >= len(s.Steps) {
+		return 1
+	}
+	return max(s.Steps[slide], 1)
+}
+
 func bufferIsNumeric(buffer string) bool {
 	_, err := strconv.Atoi(buffer)
 	return err == nil
 }
 
-func navigateNext(state State) int {
-	return repeatableAction(func(slide, totalSlides int) int {
-		if slide < totalSlides-1 {
-			return slide + 1
+func navigateNext(state State) position {
+	return repeatableAction(func(p position, state State) position {
+		switch {
+		case p.step < state.steps(p.page)-1:
+			return position{p.page, p.step + 1}
+		case p.page < state.TotalSlides-1:
+			return position{page: p.page + 1}
+		default:
+			return p
 		}
-
-		return totalSlides - 1
 	}, state)
 }
 
@@ -100,32 +111,36 @@ func navigateSlide(buffer string, totalSlides int) int {
 	return destinationSlide
 }
 
-func navigatePrevious(state State) int {
-	return repeatableAction(func(slide, totalSlides int) int {
-		if slide > 0 {
-			return slide - 1
+func navigatePrevious(state State) position {
+	return repeatableAction(func(p position, state State) position {
+		switch {
+		case p.step > 0:
+			return position{p.page, p.step - 1}
+		case p.page > 0:
+			// the previous slide is fully revealed
+			return position{p.page - 1, state.steps(p.page-1) - 1}
+		default:
+			return p
 		}
-
-		return slide
 	}, state)
 }
 
-func repeatableAction(fn repeatableFunc, state State) int {
+func repeatableAction(fn repeatableFunc, state State) position {
+	current := position{state.Page, state.Step}
 	if !bufferIsNumeric(state.Buffer) {
-		return fn(state.Page, state.TotalSlides)
+		return fn(current, state)
 	}
 
 	repeat, _ := strconv.Atoi(state.Buffer)
-	page := state.Page
 
 	if repeat == 0 {
 		// This is how behaviour works in Vim, so following principle of least astonishment.
-		return fn(state.Page, state.TotalSlides)
+		return fn(current, state)
 	}
 
 	for i := 0; i < repeat; i++ {
-		page = fn(page, state.TotalSlides)
+		current = fn(current, state)
 	}
 
-	return page
+	return current
 }
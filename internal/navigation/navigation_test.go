@@ -45,3 +45,35 @@ func TestNavigation(t *testing.T) {
 		})
 	}
 }
+
+func TestNavigationSteps(t *testing.T) {
+	steps := []int{1, 3, 0, 2}
+	tests := []struct {
+		keys string
+		page int
+		step int
+	}{
+		{keys: "l", page: 1, step: 0},
+		{keys: "ll", page: 1, step: 1},
+		{keys: "llll", page: 2, step: 0},
+		{keys: "lllh", page: 1, step: 1},
+		{keys: "lllhh", page: 1, step: 0},
+		{keys: "llllh", page: 1, step: 2},
+		{keys: "4l", page: 2, step: 0},
+		{keys: "9l", page: 3, step: 1},
+		{keys: "llG", page: 3, step: 0},
+		{keys: "llgg", page: 0, step: 0},
+		{keys: "2Gh", page: 0, step: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keys, func(t *testing.T) {
+			state := State{TotalSlides: 4, Steps: steps}
+			for _, key := range strings.Split(tt.keys, "") {
+				state = Navigate(state, key)
+			}
+
+			assert.Equal(t, State{Page: tt.page, Step: tt.step, TotalSlides: 4, Steps: steps}, state)
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package preprocessor
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/c0rydoras/folien/pkg/util"
+)
+
+// ReferencesTitle is the title of the generated slide listing the cited
+// works.
+const ReferencesTitle = "References"
+
+var (
+	// citations followed by a parenthesis are links
+	citationRegexp    = regexp.MustCompile(`\[(@[^\[\]]+)\](\(?)`)
+	citationKeyRegexp = regexp.MustCompile(`^@([\w:.#$%&+?<>~/-]+)(.*)$`)
+)
+
+// Reference is an entry of a bibliography.
+type Reference struct {
+	Key     string
+	Authors []Author
+	Year    string
+	Title   string
+	// Container is the journal, the book or the publisher of the work.
+	Container string
+	URL       string
+}
+
+// Author is an author of a referenced work, organizations only have a
+// family name.
+type Author struct {
+	Family, Given string
+}
+
+// LoadBibliography reads a BibTeX (.bib) or CSL-JSON (.json) file.
+func LoadBibliography(path string) (map[string]Reference, error) {
+	data, err := util.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var references []Reference
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".bib", ".bibtex":
+		references, err = parseBibTeX(data)
+	case ".json":
+		references, err = parseCSLJSON(data)
+	default:
+		return nil, fmt.Errorf("unsupported bibliography format %q", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bibliography := make(map[string]Reference, len(references))
+	for _, reference := range references {
+		bibliography[reference.Key] = reference
+	}
+	return bibliography, nil
+}
+
+// Cite replaces citations ([@key], [@key, p. 12] or [@a; @b]) with the
+// authors and the year of the cited works, e.g. (Knuth 1984, p. 12), and
+// appends a slide listing the cited works. Unknown keys are marked with a
+// question mark.
+func Cite(folien []string, bibliography map[string]Reference) []string {
+	result := make([]string, len(folien))
+	var cited []Reference
+	for i, slide := range folien {
+		result[i] = replaceOutsideCode(slide, citationRegexp, func(match []string) (string, bool) {
+			if match[2] != "" {
+				return "", false
+			}
+			var citations []string
+			for _, part := range strings.Split(match[1], ";") {
+				matches := citationKeyRegexp.FindStringSubmatch(strings.TrimSpace(part))
+				if matches == nil {
+					return "", false
+				}
+				key, locator := matches[1], strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(matches[2]), ","))
+
+				citation := "?" + key
+				if reference, ok := bibliography[key]; ok {
+					citation = reference.label()
+					if !slices.ContainsFunc(cited, func(r Reference) bool { return r.Key == key }) {
+						cited = append(cited, reference)
+					}
+				}
+				if locator != "" {
+					citation += ", " + locator
+				}
+				citations = append(citations, citation)
+			}
+			return "(" + strings.Join(citations, "; ") + ")", true
+		})
+	}
+	if len(cited) == 0 {
+		return result
+	}
+
+	slices.SortStableFunc(cited, func(a, b Reference) int {
+		return cmp.Or(
+			cmp.Compare(a.authorLabel(), b.authorLabel()),
+			cmp.Compare(a.Year, b.Year),
+			cmp.Compare(a.Title, b.Title),
+		)
+	})
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", ReferencesTitle)
+	for _, reference := range cited {
+		fmt.Fprintf(&b, "- %s\n", reference.String())
+	}
+	return append(result, b.String())
+}
+
+// label returns the authors and the year of the reference.
+func (r Reference) label() string {
+	return r.authorLabel() + " " + cmp.Or(r.Year, "n.d.")
+}
+
+// authorLabel returns the family names of the authors, the title if there
+// are none.
+func (r Reference) authorLabel() string {
+	switch len(r.Authors) {
+	case 0:
+		return r.Title
+	case 1:
+		return r.Authors[0].Family
+	case 2:
+		return r.Authors[0].Family + " and " + r.Authors[1].Family
+	default:
+		return r.Authors[0].Family + " et al."
+	}
+}
+
+// String formats the reference for the list of references, e.g.
+//
+//	Donald E. Knuth (1984). *Literate Programming*. The Computer Journal.
+func (r Reference) String() string {
+	names := make([]string, len(r.Authors))
+	for i, author := range r.Authors {
+		names[i] = strings.TrimSpace(author.Given + " " + author.Family)
+	}
+
+	var b strings.Builder
+	switch len(names) {
+	case 0:
+	case 1:
+		b.WriteString(names[0] + " ")
+	default:
+		b.WriteString(strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1] + " ")
+	}
+	fmt.Fprintf(&b, "(%s). ", cmp.Or(r.Year, "n.d."))
+	fmt.Fprintf(&b, "*%s*.", r.Title)
+	if r.Container != "" {
+		fmt.Fprintf(&b, " %s.", r.Container)
+	}
+	if r.URL != "" {
+		fmt.Fprintf(&b, " <%s>", r.URL)
+	}
+	return b.String()
+}
+
+// parseBibTeX parses the entries of a BibTeX file, @string, @preamble and
+// @comment entries are skipped.
+func parseBibTeX(data string) ([]Reference, error) {
+	var references []Reference
+	for {
+		at := strings.IndexByte(data, '@')
+		if at < 0 {
+			return references, nil
+		}
+		data = data[at+1:]
+
+		open := strings.IndexAny(data, "{(")
+		if open < 0 {
+			return nil, fmt.Errorf("invalid BibTeX entry")
+		}
+		kind := strings.ToLower(strings.TrimSpace(data[:open]))
+		end := matchingBrace(data, open)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated BibTeX entry @%s", kind)
+		}
+		body := data[open+1 : end]
+		data = data[end+1:]
+		if kind == "string" || kind == "preamble" || kind == "comment" {
+			continue
+		}
+
+		key, fields, _ := strings.Cut(body, ",")
+		reference, err := bibTeXReference(strings.TrimSpace(key), fields)
+		if err != nil {
+			return nil, err
+		}
+		references = append(references, reference)
+	}
+}
+
+func bibTeXReference(key string, body string) (Reference, error) {
+	fields := map[string]string{}
+	for {
+		body = strings.TrimLeft(body, " \t\r\n,")
+		if body == "" {
+			break
+		}
+		name, rest, ok := strings.Cut(body, "=")
+		if !ok {
+			return Reference{}, fmt.Errorf("invalid field in BibTeX entry %s", key)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		rest = strings.TrimLeft(rest, " \t\r\n")
+
+		var value string
+		switch {
+		case strings.HasPrefix(rest, "{"):
+			end := matchingBrace(rest, 0)
+			if end < 0 {
+				return Reference{}, fmt.Errorf("unterminated field %s in BibTeX entry %s", name, key)
+			}
+			value, body = rest[1:end], rest[end+1:]
+		case strings.HasPrefix(rest, `"`):
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return Reference{}, fmt.Errorf("unterminated field %s in BibTeX entry %s", name, key)
+			}
+			value, body = rest[1:end+1], rest[end+2:]
+		default:
+			end := strings.IndexByte(rest, ',')
+			if end < 0 {
+				end = len(rest)
+			}
+			value, body = rest[:end], rest[end:]
+		}
+		fields[name] = strings.TrimSpace(value)
+	}
+
+	reference := Reference{
+		Key:       key,
+		Year:      fields["year"],
+		Title:     stripBraces(fields["title"]),
+		Container: stripBraces(cmp.Or(fields["journal"], fields["booktitle"], fields["publisher"], fields["howpublished"])),
+		URL:       fields["url"],
+	}
+	if reference.URL == "" && fields["doi"] != "" {
+		reference.URL = "https://doi.org/" + fields["doi"]
+	}
+	for _, name := range splitBibTeXNames(fields["author"]) {
+		reference.Authors = append(reference.Authors, bibTeXAuthor(name))
+	}
+	return reference, nil
+}
+
+// splitBibTeXNames splits the names separated by "and" outside of braces.
+func splitBibTeXNames(names string) []string {
+	var (
+		result []string
+		depth  int
+		start  int
+	)
+	fields := strings.Fields(names)
+	for i, field := range fields {
+		depth += strings.Count(field, "{") - strings.Count(field, "}")
+		if depth == 0 && field == "and" {
+			result = append(result, strings.Join(fields[start:i], " "))
+			start = i + 1
+		}
+	}
+	if start < len(fields) {
+		result = append(result, strings.Join(fields[start:], " "))
+	}
+	return result
+}
+
+// bibTeXAuthor parses "Family, Given" and "Given Family", names in braces
+// are taken as they are, e.g. {Acme Inc.}.
+func bibTeXAuthor(name string) Author {
+	if strings.HasPrefix(name, "{") && matchingBrace(name, 0) == len(name)-1 {
+		return Author{Family: stripBraces(name)}
+	}
+	name = stripBraces(name)
+	if family, given, ok := strings.Cut(name, ","); ok {
+		return Author{Family: strings.TrimSpace(family), Given: strings.TrimSpace(given)}
+	}
+	parts := strings.Fields(name)
+	if len(parts) == 0 {
+		return Author{}
+	}
+	return Author{Family: parts[len(parts)-1], Given: strings.Join(parts[:len(parts)-1], " ")}
+}
+
+// matchingBrace returns the index of the brace closing the one at open.
+func matchingBrace(s string, open int) int {
+	closing := byte('}')
+	if s[open] == '(' {
+		closing = ')'
+	}
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case s[open]:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func stripBraces(s string) string {
+	s = strings.NewReplacer("{", "", "}", "").Replace(s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// cslItem is an entry of a CSL-JSON bibliography.
+type cslItem struct {
+	ID     string `json:"id"`
+	Author []struct {
+		Family  string `json:"family"`
+		Given   string `json:"given"`
+		Literal string `json:"literal"`
+	} `json:"author"`
+	Issued struct {
+		DateParts [][]any `json:"date-parts"`
+	} `json:"issued"`
+	Title          string `json:"title"`
+	ContainerTitle string `json:"container-title"`
+	Publisher      string `json:"publisher"`
+	URL            string `json:"URL"`
+	DOI            string `json:"DOI"`
+}
+
+func parseCSLJSON(data string) ([]Reference, error) {
+	var items []cslItem
+	if err := json.Unmarshal([]byte(data), &items); err != nil {
+		return nil, err
+	}
+
+	references := make([]Reference, len(items))
+	for i, item := range items {
+		reference := Reference{
+			Key:       item.ID,
+			Title:     item.Title,
+			Container: cmp.Or(item.ContainerTitle, item.Publisher),
+			URL:       item.URL,
+		}
+		if reference.URL == "" && item.DOI != "" {
+			reference.URL = "https://doi.org/" + item.DOI
+		}
+		if parts := item.Issued.DateParts; len(parts) > 0 && len(parts[0]) > 0 {
+			reference.Year = fmt.Sprint(parts[0][0])
+		}
+		for _, author := range item.Author {
+			reference.Authors = append(reference.Authors, Author{
+				Family: cmp.Or(author.Family, author.Literal),
+				Given:  author.Given,
+			})
+		}
+		references[i] = reference
+	}
+	return references, nil
+}
@@ -0,0 +1,134 @@
+package preprocessor
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/c0rydoras/folien/internal/meta"
+)
+
+const bibTeX = `@string{cj = "The Computer Journal"}
+
+@article{knuth84,
+  author  = {Knuth, Donald E.},
+  title   = {{Literate} Programming},
+  journal = {The Computer Journal},
+  year    = 1984,
+}
+
+@book{gof,
+  author    = "Erich Gamma and Richard Helm and Ralph Johnson and John Vlissides",
+  title     = {Design Patterns},
+  publisher = {Addison-Wesley},
+  year      = {1994}
+}
+
+@misc{rfc,
+  author = {{Internet Engineering Task Force}},
+  title  = {HTTP Semantics},
+  doi    = {10.17487/RFC9110},
+}
+`
+
+const cslJSON = `[
+  {"id": "lamport94", "author": [{"family": "Lamport", "given": "Leslie"}],
+   "issued": {"date-parts": [[1994, 5]]}, "title": "LaTeX", "publisher": "Addison-Wesley"}
+]`
+
+func TestLoadBibliography(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"refs.bib": bibTeX, "refs.json": cslJSON})
+
+	bibliography, err := LoadBibliography(filepath.Join(dir, "refs.bib"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]Reference{
+		"knuth84": {
+			Key:       "knuth84",
+			Authors:   []Author{{Family: "Knuth", Given: "Donald E."}},
+			Year:      "1984",
+			Title:     "Literate Programming",
+			Container: "The Computer Journal",
+		},
+		"gof": {
+			Key: "gof",
+			Authors: []Author{
+				{Family: "Gamma", Given: "Erich"},
+				{Family: "Helm", Given: "Richard"},
+				{Family: "Johnson", Given: "Ralph"},
+				{Family: "Vlissides", Given: "John"},
+			},
+			Year:      "1994",
+			Title:     "Design Patterns",
+			Container: "Addison-Wesley",
+		},
+		"rfc": {
+			Key:     "rfc",
+			Authors: []Author{{Family: "Internet Engineering Task Force"}},
+			Title:   "HTTP Semantics",
+			URL:     "https://doi.org/10.17487/RFC9110",
+		},
+	}
+	if !reflect.DeepEqual(bibliography, expected) {
+		t.Errorf("LoadBibliography() = %+v, want %+v", bibliography, expected)
+	}
+
+	bibliography, err = LoadBibliography(filepath.Join(dir, "refs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lamport := Reference{
+		Key:       "lamport94",
+		Authors:   []Author{{Family: "Lamport", Given: "Leslie"}},
+		Year:      "1994",
+		Title:     "LaTeX",
+		Container: "Addison-Wesley",
+	}
+	if !reflect.DeepEqual(bibliography, map[string]Reference{"lamport94": lamport}) {
+		t.Errorf("LoadBibliography() = %+v", bibliography)
+	}
+}
+
+func TestCite(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"refs.bib": bibTeX})
+	bibliography, err := LoadBibliography(filepath.Join(dir, "refs.bib"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	folien := []string{
+		"# Patterns\n\nSee [@gof, p. 12] and [@knuth84; @rfc].",
+		"Unknown [@nobody], code `[@gof]`, links [@gof](https://example.com) and [@gof]",
+	}
+	expected := []string{
+		"# Patterns\n\nSee (Gamma et al. 1994, p. 12) and (Knuth 1984; Internet Engineering Task Force n.d.).",
+		"Unknown (?nobody), code `[@gof]`, links [@gof](https://example.com) and (Gamma et al. 1994)",
+		"# References\n\n" +
+			"- Erich Gamma, Richard Helm, Ralph Johnson and John Vlissides (1994). *Design Patterns*. Addison-Wesley.\n" +
+			"- Internet Engineering Task Force (n.d.). *HTTP Semantics*. <https://doi.org/10.17487/RFC9110>\n" +
+			"- Donald E. Knuth (1984). *Literate Programming*. The Computer Journal.\n",
+	}
+	if result := Cite(folien, bibliography); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Cite() =\n%q\nwant\n%q", result, expected)
+	}
+}
+
+func TestCitationsStage(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"refs.bib": bibTeX})
+
+	config := NewConfig().WithBaseDir(dir).WithMeta(&meta.Meta{Bibliography: "refs.bib"})
+	result, err := config.Process([]string{"# Intro\n\n[@knuth84]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || !strings.HasPrefix(result[1], "# References") {
+		t.Errorf("expected a references slide, got %q", result)
+	}
+
+	config = NewConfig().WithBaseDir(dir).WithMeta(&meta.Meta{Bibliography: "missing.bib"})
+	if _, err := config.Process([]string{"[@knuth84]"}); err == nil || !strings.Contains(err.Error(), "could not load bibliography missing.bib") {
+		t.Errorf("Process() error = %v", err)
+	}
+}
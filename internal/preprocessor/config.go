@@ -1,9 +1,37 @@
 package preprocessor
 
+import (
+	"maps"
+	"path/filepath"
+
+	"github.com/c0rydoras/folien/internal/meta"
+)
+
 type Config struct {
 	TOCTitle       string
 	TOCDescription string
+	TOC            TOCOptions
 	EnableHeadings bool
+	// HeadingDepth and HeadingMode configure the inherited headings, they
+	// take precedence over the frontmatter.
+	HeadingDepth int
+	HeadingMode  string
+	// NumberHeadings prefixes the headings with their section number.
+	NumberHeadings bool
+	// Vars are the template variables from the command line, they take
+	// precedence over the variables from the frontmatter.
+	Vars map[string]string
+	// Pipeline is the order of the stages, see DefaultPipeline. It takes
+	// precedence over the pipeline of the frontmatter.
+	Pipeline []string
+	// Filters are commands of external filters run after the pipeline.
+	Filters []string
+	// AllowExecution allows running the external filters of the frontmatter.
+	AllowExecution bool
+	// BaseDir is the directory external filters are run in.
+	BaseDir string
+	// Meta is the frontmatter of the presentation.
+	Meta *meta.Meta
 }
 
 func NewConfig() *Config {
@@ -20,21 +48,134 @@ func (c *Config) WithTOC(title string, description string) *Config {
 	return c
 }
 
+func (c *Config) WithTOCOptions(options TOCOptions) *Config {
+	c.TOC = options
+	return c
+}
+
 func (c *Config) WithHeadings() *Config {
 	c.EnableHeadings = true
 	return c
 }
 
-func (c *Config) Process(folien []string) []string {
+func (c *Config) WithHeadingDepth(depth int) *Config {
+	c.HeadingDepth = depth
+	return c
+}
+
+func (c *Config) WithHeadingMode(mode string) *Config {
+	c.HeadingMode = mode
+	return c
+}
+
+func (c *Config) WithNumbering() *Config {
+	c.NumberHeadings = true
+	return c
+}
+
+func (c *Config) WithVars(vars map[string]string) *Config {
+	c.Vars = vars
+	return c
+}
+
+func (c *Config) WithPipeline(stages []string, filters []string) *Config {
+	c.Pipeline = stages
+	c.Filters = filters
+	return c
+}
+
+func (c *Config) WithExecution() *Config {
+	c.AllowExecution = true
+	return c
+}
+
+func (c *Config) WithBaseDir(dir string) *Config {
+	c.BaseDir = dir
+	return c
+}
+
+// WithMeta returns a copy of the config for a presentation with the given
+// frontmatter, the config itself is not changed as it is reused when the
+// presentation is reloaded.
+func (c *Config) WithMeta(m *meta.Meta) *Config {
+	config := *c
+	config.Meta = m
+	return &config
+}
+
+// Process runs the stages of the pipeline on the folien. The slide numbers of
+// the table of contents are resolved at last.
+func (c *Config) Process(folien []string) ([]string, error) {
+	stages, err := c.pipeline()
+	if err != nil {
+		return nil, err
+	}
+
 	result := folien
+	for _, stage := range stages {
+		result, err = stage.Process(result, c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return numberTOC(result), nil
+}
 
-	if c.EnableHeadings {
-		result = AddHeadings(result, 2)
+// bibliography returns the path of the bibliography of the frontmatter,
+// relative paths are relative to BaseDir.
+func (c *Config) bibliography() string {
+	if c.Meta == nil || c.Meta.Bibliography == "" {
+		return ""
+	}
+	if filepath.IsAbs(c.Meta.Bibliography) {
+		return c.Meta.Bibliography
 	}
+	return filepath.Join(c.BaseDir, c.Meta.Bibliography)
+}
+
+// Numbering returns whether headings are numbered, either on the command line
+// or in the frontmatter.
+func (c *Config) Numbering() bool {
+	return c.NumberHeadings || (c.Meta != nil && c.Meta.Headings.Numbered)
+}
 
-	if c.TOCTitle != "" {
-		result = append([]string{GenerateTOC(folien, c.TOCTitle, c.TOCDescription)}, result...)
+// headings returns the depth and the mode of the inherited headings and
+// whether they are enabled, either on the command line or in the frontmatter.
+func (c *Config) headings() (int, string, bool) {
+	depth, mode := c.HeadingDepth, c.HeadingMode
+	enabled := c.EnableHeadings || depth != 0 || mode != ""
+	if c.Meta != nil {
+		enabled = enabled || c.Meta.Headings.Depth != 0 || c.Meta.Headings.Mode != ""
+		if depth == 0 {
+			depth = c.Meta.Headings.Depth
+		}
+		if mode == "" {
+			mode = c.Meta.Headings.Mode
+		}
 	}
+	if depth == 0 {
+		depth = DefaultHeadingDepth
+	}
+	return depth, mode, enabled
+}
 
-	return result
+// templateData returns the data available in templates of all folien.
+func (c *Config) templateData(folien []string) TemplateData {
+	data := TemplateData{Vars: map[string]any{}}
+	if c.Meta != nil {
+		data.Title, data.Author, data.Date = c.Meta.Title, c.Meta.Author, c.Meta.Date
+		maps.Copy(data.Vars, c.Meta.Vars)
+	}
+	for key, value := range c.Vars {
+		data.Vars[key] = value
+	}
+	if data.Title == "" {
+		for _, slide := range folien {
+			if headings := collectH1s([]byte(slide)); len(headings) > 0 && !IsTOC(slide) {
+				data.Title = headings[0]
+				break
+			}
+		}
+	}
+	return data
 }
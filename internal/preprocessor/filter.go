@@ -0,0 +1,91 @@
+package preprocessor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// FilterTimeout is the time an external filter may take.
+const FilterTimeout = 30 * time.Second
+
+// Filter is a stage running an external program. The program receives a
+// FilterDocument as JSON on stdin and writes the document with the modified
+// folien to stdout.
+type Filter struct {
+	// Command is the program with its arguments, separated by spaces.
+	Command string
+	// Dir is the directory the program is run in.
+	Dir string
+}
+
+// FilterDocument is exchanged with external filters.
+type FilterDocument struct {
+	Folien []string       `json:"folien"`
+	Meta   FilterMetadata `json:"meta"`
+}
+
+// FilterMetadata is the metadata of the presentation passed to external
+// filters.
+type FilterMetadata struct {
+	Title  string         `json:"title,omitempty"`
+	Author string         `json:"author,omitempty"`
+	Date   string         `json:"date,omitempty"`
+	Theme  string         `json:"theme,omitempty"`
+	Vars   map[string]any `json:"vars,omitempty"`
+}
+
+// Process runs the filter.
+func (f Filter) Process(folien []string, c *Config) ([]string, error) {
+	args := strings.Fields(f.Command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty filter command")
+	}
+
+	data := c.templateData(folien)
+	doc := FilterDocument{
+		Folien: folien,
+		Meta: FilterMetadata{
+			Title:  data.Title,
+			Author: data.Author,
+			Date:   data.Date,
+			Vars:   data.Vars,
+		},
+	}
+	if c.Meta != nil {
+		doc.Meta.Theme = c.Meta.Theme
+	}
+	input, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), FilterTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = f.Dir
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("filter %s failed: %w", args[0], err)
+	}
+
+	var result FilterDocument
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, fmt.Errorf("filter %s returned invalid JSON: %w", args[0], err)
+	}
+	if result.Folien == nil {
+		return nil, fmt.Errorf("filter %s returned no folien", args[0])
+	}
+	return result.Folien, nil
+}
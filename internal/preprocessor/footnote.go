@@ -0,0 +1,191 @@
+package preprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/c0rydoras/folien/pkg/parser"
+)
+
+var (
+	// footnoteDefinitionRegexp matches the first line of a footnote
+	// definition, e.g. "[^1]: Text", continuation lines are indented.
+	footnoteDefinitionRegexp = regexp.MustCompile(`^ {0,3}\[\^([^\]\s]+)\]:[ \t]*(.*)$`)
+	footnoteReferenceRegexp  = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+)
+
+var superscripts = []rune("⁰¹²³⁴⁵⁶⁷⁸⁹")
+
+// AddFootnotes replaces footnote references ([^id]) with superscript numbers
+// and lists the referenced footnotes at the bottom of the slide. Footnotes
+// can be defined on any slide ([^id]: Text), the definitions are removed.
+// Footnotes are numbered per slide, references without a definition are
+// kept. As the footnotes follow the content, they are only revealed at the
+// last step of folien with pauses.
+func AddFootnotes(folien []string) []string {
+	definitions := map[string]string{}
+	result := make([]string, len(folien))
+	for i, slide := range folien {
+		result[i] = removeFootnoteDefinitions(slide, definitions)
+	}
+	if len(definitions) == 0 {
+		return result
+	}
+
+	for i, slide := range result {
+		var footnotes []string
+		numbers := map[string]int{}
+		slide = replaceOutsideCode(slide, footnoteReferenceRegexp, func(match []string) (string, bool) {
+			text, ok := definitions[match[1]]
+			if !ok {
+				return "", false
+			}
+			number, ok := numbers[match[1]]
+			if !ok {
+				footnotes = append(footnotes, text)
+				number = len(footnotes)
+				numbers[match[1]] = number
+			}
+			return superscript(number), true
+		})
+		if len(footnotes) == 0 {
+			continue
+		}
+
+		var b strings.Builder
+		b.WriteString(strings.TrimRight(slide, "\n"))
+		b.WriteString("\n\n---\n")
+		for n, text := range footnotes {
+			fmt.Fprintf(&b, "\n%s %s\n", superscript(n+1), text)
+		}
+		result[i] = b.String()
+	}
+	return result
+}
+
+// removeFootnoteDefinitions removes the footnote definitions of the slide and
+// adds them to definitions.
+func removeFootnoteDefinitions(slide string, definitions map[string]string) string {
+	if !strings.Contains(slide, "[^") {
+		return slide
+	}
+
+	protected := codeRanges(slide)
+	var (
+		b       strings.Builder
+		current string
+		offset  int
+	)
+	for _, line := range strings.SplitAfter(slide, "\n") {
+		start := offset
+		offset += len(line)
+		text := strings.TrimSuffix(line, "\n")
+
+		if current != "" && (strings.HasPrefix(text, "    ") || strings.HasPrefix(text, "\t")) && !inRanges(protected, start) {
+			definitions[current] += " " + strings.TrimSpace(text)
+			continue
+		}
+		current = ""
+		if matches := footnoteDefinitionRegexp.FindStringSubmatch(text); matches != nil && !inRanges(protected, start) {
+			current = matches[1]
+			definitions[current] = strings.TrimSpace(matches[2])
+			continue
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+func superscript(n int) string {
+	var b strings.Builder
+	for _, digit := range fmt.Sprint(n) {
+		b.WriteRune(superscripts[digit-'0'])
+	}
+	return b.String()
+}
+
+// replaceOutsideCode replaces the matches of the pattern which are not part of
+// code blocks or code spans with the result of replace, matches for which
+// replace returns false are kept.
+func replaceOutsideCode(slide string, pattern *regexp.Regexp, replace func(match []string) (string, bool)) string {
+	protected := codeRanges(slide)
+
+	var b strings.Builder
+	last := 0
+	for _, indices := range pattern.FindAllStringSubmatchIndex(slide, -1) {
+		if inRanges(protected, indices[0]) {
+			continue
+		}
+		match := make([]string, len(indices)/2)
+		for i := range match {
+			if indices[2*i] >= 0 {
+				match[i] = slide[indices[2*i]:indices[2*i+1]]
+			}
+		}
+		replacement, ok := replace(match)
+		if !ok {
+			continue
+		}
+		b.WriteString(slide[last:indices[0]])
+		b.WriteString(replacement)
+		last = indices[1]
+	}
+	b.WriteString(slide[last:])
+	return b.String()
+}
+
+// codeRanges returns the ranges of the fenced and indented code blocks and
+// the code spans of the slide.
+func codeRanges(slide string) [][2]int {
+	source := []byte(slide)
+	var ranges [][2]int
+	for _, block := range parser.CollectCodeBlocks(source) {
+		start, stop := parser.BlockRange(block, source)
+		ranges = append(ranges, [2]int{start, stop})
+	}
+	ranges = append(ranges, parser.IndentedCodeRanges(source)...)
+
+	// code spans end with a run of backticks of the same length
+	for i := 0; i < len(slide); {
+		if slide[i] != '`' || inRanges(ranges, i) {
+			i++
+			continue
+		}
+		run := len(slide[i:]) - len(strings.TrimLeft(slide[i:], "`"))
+		end := closingBackticks(slide, i+run, run)
+		if end < 0 {
+			i += run
+			continue
+		}
+		ranges = append(ranges, [2]int{i, end})
+		i = end
+	}
+	return ranges
+}
+
+// closingBackticks returns the end of the run of exactly n backticks
+// following the offset.
+func closingBackticks(s string, offset int, n int) int {
+	for i := offset; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		run := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+		if run == n {
+			return i + run
+		}
+		i += run
+	}
+	return -1
+}
+
+func inRanges(ranges [][2]int, offset int) bool {
+	for _, r := range ranges {
+		if offset >= r[0] && offset < r[1] {
+			return true
+		}
+	}
+	return false
+}
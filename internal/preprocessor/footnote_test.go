@@ -0,0 +1,44 @@
+package preprocessor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddFootnotes(t *testing.T) {
+	tests := []struct {
+		name     string
+		folien   []string
+		expected []string
+	}{
+		{
+			name: "Footnotes are numbered per slide",
+			folien: []string{
+				"# A\n\nFirst[^a] and second[^b], first again[^a].\n\n[^a]: Note A\n[^b]: Note B\n    continued\n",
+				"# B\n\nAgain[^b].",
+			},
+			expected: []string{
+				"# A\n\nFirst¹ and second², first again¹.\n\n---\n\n¹ Note A\n\n² Note B continued\n",
+				"# B\n\nAgain¹.\n\n---\n\n¹ Note B continued\n",
+			},
+		},
+		{
+			name:     "Code is kept",
+			folien:   []string{"`[^a]`\n\n```\n[^a]: code\n```\n\nText[^a]\n\n[^a]: Note"},
+			expected: []string{"`[^a]`\n\n```\n[^a]: code\n```\n\nText¹\n\n---\n\n¹ Note\n"},
+		},
+		{
+			name:     "Undefined references are kept",
+			folien:   []string{"Text[^x]"},
+			expected: []string{"Text[^x]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := AddFootnotes(tt.folien); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("AddFootnotes() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package preprocessor
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/yuin/goldmark"
@@ -35,19 +36,37 @@ func collectHeadings(slideContent []byte) map[int]string {
 
 }
 
-func AddHeadings(folien []string, maxLevel int) []string {
-	if len(folien) == 0 {
-		return folien
-	}
+// Modes of displaying the headings inherited from previous folien.
+const (
+	// HeadingsInline prepends the inherited headings to the slide.
+	HeadingsInline = "inline"
+	// HeadingsBreadcrumb displays the inherited headings in the status bar.
+	HeadingsBreadcrumb = "breadcrumb"
+)
 
-	var newSlides []string
+// DefaultHeadingDepth is the deepest heading level inherited by default.
+const DefaultHeadingDepth = 2
+
+// breadcrumbSeparator separates the headings of a breadcrumb.
+const breadcrumbSeparator = " › "
+
+var breadcrumbRegexp = regexp.MustCompile(`(?m)^<!-- breadcrumb: (.*) -->\n?`)
+
+// inheritedHeadings returns for each slide the headings up to maxLevel it
+// inherits from the previous folien, i.e. the levels it has no heading for.
+func inheritedHeadings(folien []string, maxLevel int) [][]heading {
+	var inherited [][]heading
 
 	acc := make(map[int]string)
 
 	for _, slideContent := range folien {
+		if IsTOC(slideContent) {
+			inherited = append(inherited, nil)
+			continue
+		}
 		currentHeadings := collectHeadings([]byte(slideContent))
 
-		headingsToAdd := make(map[int]string)
+		var headingsToAdd []heading
 		for level := 1; level <= maxLevel; level++ {
 			if newHeading, ok := currentHeadings[level]; ok {
 				acc[level] = newHeading
@@ -56,22 +75,71 @@ func AddHeadings(folien []string, maxLevel int) []string {
 				}
 			} else {
 				if inheritedHeading, ok := acc[level]; ok {
-					headingsToAdd[level] = inheritedHeading
+					headingsToAdd = append(headingsToAdd, heading{level: level, text: inheritedHeading})
 				}
 			}
 		}
 
+		inherited = append(inherited, headingsToAdd)
+	}
+
+	return inherited
+}
+
+type heading struct {
+	level int
+	text  string
+}
+
+func AddHeadings(folien []string, maxLevel int) []string {
+	if len(folien) == 0 {
+		return folien
+	}
+
+	var newSlides []string
+
+	for i, headings := range inheritedHeadings(folien, maxLevel) {
 		var prefix strings.Builder
-		for level := 1; level <= maxLevel; level++ {
-			if headingText, ok := headingsToAdd[level]; ok {
-				line := fmt.Sprintf("%s %s\n", strings.Repeat("#", level), headingText)
-				prefix.WriteString(line)
-			}
+		for _, h := range headings {
+			line := fmt.Sprintf("%s %s\n", strings.Repeat("#", h.level), h.text)
+			prefix.WriteString(line)
 		}
 
-		newSlide := prefix.String() + slideContent
+		newSlide := prefix.String() + folien[i]
 		newSlides = append(newSlides, newSlide)
 	}
 
 	return newSlides
 }
+
+// AddBreadcrumbs marks the folien with the headings they inherit from previous
+// folien, which are displayed as a breadcrumb (e.g. Architecture › Storage) in
+// the status bar instead of being added to the slide. See Breadcrumb.
+func AddBreadcrumbs(folien []string, maxLevel int) []string {
+	var newSlides []string
+
+	for i, headings := range inheritedHeadings(folien, maxLevel) {
+		if len(headings) == 0 {
+			newSlides = append(newSlides, folien[i])
+			continue
+		}
+
+		texts := make([]string, len(headings))
+		for j, h := range headings {
+			texts[j] = h.text
+		}
+		comment := fmt.Sprintf("<!-- breadcrumb: %s -->\n", strings.Join(texts, breadcrumbSeparator))
+		newSlides = append(newSlides, comment+folien[i])
+	}
+
+	return newSlides
+}
+
+// Breadcrumb returns the breadcrumb added to the slide by AddBreadcrumbs.
+func Breadcrumb(slide string) string {
+	matches := breadcrumbRegexp.FindStringSubmatch(slide)
+	if matches == nil {
+		return ""
+	}
+	return matches[1]
+}
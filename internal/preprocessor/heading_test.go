@@ -3,6 +3,8 @@ package preprocessor
 import (
 	"reflect"
 	"testing"
+
+	"github.com/c0rydoras/folien/internal/meta"
 )
 
 func TestCollectHeadings(t *testing.T) {
@@ -86,3 +88,69 @@ func TestAddHeadings(t *testing.T) {
 		t.Errorf("AddHeadings() = %v, want %v", result, expected)
 	}
 }
+
+func TestAddBreadcrumbs(t *testing.T) {
+	folien := []string{
+		"# Architecture\nOverview",
+		"## Storage\nContent",
+		"### Replication\nContent",
+		"# Summary",
+	}
+
+	expected := []string{
+		"# Architecture\nOverview",
+		"<!-- breadcrumb: Architecture -->\n## Storage\nContent",
+		"<!-- breadcrumb: Architecture › Storage -->\n### Replication\nContent",
+		"# Summary",
+	}
+
+	result := AddBreadcrumbs(folien, 3)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("AddBreadcrumbs() = %q, want %q", result, expected)
+	}
+
+	if breadcrumb := Breadcrumb(result[2]); breadcrumb != "Architecture › Storage" {
+		t.Errorf("Breadcrumb() = %q, want %q", breadcrumb, "Architecture › Storage")
+	}
+	if breadcrumb := Breadcrumb(result[0]); breadcrumb != "" {
+		t.Errorf("Breadcrumb() = %q, want empty", breadcrumb)
+	}
+}
+
+func TestProcessHeadingsFromFrontmatter(t *testing.T) {
+	folien := []string{"# A", "## B", "### C"}
+
+	tests := []struct {
+		name     string
+		config   *Config
+		expected []string
+	}{
+		{
+			name:     "Disabled",
+			config:   NewConfig(),
+			expected: folien,
+		},
+		{
+			name:     "Depth from frontmatter",
+			config:   NewConfig().WithMeta(&meta.Meta{Headings: meta.Headings{Depth: 3}}),
+			expected: []string{"# A", "# A\n## B", "# A\n## B\n### C"},
+		},
+		{
+			name:     "Command line takes precedence",
+			config:   NewConfig().WithHeadingMode(HeadingsBreadcrumb).WithMeta(&meta.Meta{Headings: meta.Headings{Depth: 3, Mode: HeadingsInline}}),
+			expected: []string{"# A", "<!-- breadcrumb: A -->\n## B", "<!-- breadcrumb: A › B -->\n### C"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.config.Process(folien)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Process() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package preprocessor
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/c0rydoras/folien/pkg/parser"
+	"github.com/c0rydoras/folien/pkg/util"
+)
+
+var includeRegexp = regexp.MustCompile(`^\s*<!--\s*include:\s*(.+?)\s*-->\s*$`)
+
+// ResolveIncludes replaces include directives (<!-- include: path -->) with
+// the content of the referenced file, without its frontmatter. Paths are
+// relative to the including file, fileName is the path of the presentation
+// and may be empty if it is read from stdin. Directives inside code blocks are
+// ignored. It returns the content and all included files.
+func ResolveIncludes(content string, fileName string) (string, []string, error) {
+	var included []string
+	stack := []string{}
+	if fileName != "" && fileName != "-" {
+		if abs, err := filepath.Abs(fileName); err == nil {
+			stack = append(stack, abs)
+		}
+	}
+
+	resolved, err := resolveIncludes(content, BaseDir(fileName), stack, &included)
+	return resolved, included, err
+}
+
+func resolveIncludes(content, dir string, stack []string, included *[]string) (string, error) {
+	var (
+		b       strings.Builder
+		tracker parser.FenceTracker
+	)
+
+	lines := strings.SplitAfter(content, "\n")
+	for _, line := range lines {
+		if tracker.Inside(line) {
+			b.WriteString(line)
+			continue
+		}
+		matches := includeRegexp.FindStringSubmatch(strings.TrimRight(line, "\n"))
+		if matches == nil {
+			b.WriteString(line)
+			continue
+		}
+
+		path := matches[1]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return "", err
+		}
+		if slices.Contains(stack, path) {
+			return "", fmt.Errorf("include cycle: %s", strings.Join(append(stack, path), " -> "))
+		}
+
+		// the file is watched even if it cannot be read, so that creating or
+		// fixing it reloads the presentation
+		if !slices.Contains(*included, path) {
+			*included = append(*included, path)
+		}
+		data, err := util.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("could not include %s: %w", matches[1], err)
+		}
+
+		data = strings.ReplaceAll(data, "\r", "")
+		if parser.HasFrontMatter(data) {
+			data = parser.RemoveFrontMatter(data)
+		}
+		// snippets and tables are imported relative to the included file
+		data, snippets, err := ImportSnippets(data, filepath.Dir(path))
+		if err != nil {
+			return "", err
+		}
+		data, tables, err := ImportTables(data, filepath.Dir(path))
+		if err != nil {
+			return "", err
+		}
+		for _, snippet := range slices.Concat(snippets, tables) {
+			if !slices.Contains(*included, snippet) {
+				*included = append(*included, snippet)
+			}
+		}
+		data, err = resolveIncludes(data, filepath.Dir(path), append(stack, path), included)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(strings.TrimSuffix(data, "\n"))
+		if strings.HasSuffix(line, "\n") {
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String(), nil
+}
+
+// BaseDir returns the directory relative paths in the presentation are
+// resolved against.
+func BaseDir(fileName string) string {
+	if fileName == "" || fileName == "-" {
+		return "."
+	}
+	return filepath.Dir(fileName)
+}
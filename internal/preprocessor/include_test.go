@@ -0,0 +1,85 @@
+package preprocessor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestResolveIncludes(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"talk/deck.md":     "# Talk\n\n---\n\n<!-- include: ../common/intro.md -->\n\n---\n\n```\n<!-- include: nothing.md -->\n```\n",
+		"common/intro.md":  "---\nauthor: ignored\n---\n# Intro\n\n---\n\n<!-- include: nested.md -->\n",
+		"common/nested.md": "# Nested\n",
+	})
+
+	deck := filepath.Join(dir, "talk/deck.md")
+	content, _ := os.ReadFile(deck)
+
+	result, included, err := ResolveIncludes(string(content), deck)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# Talk\n\n---\n\n# Intro\n\n---\n\n# Nested\n\n---\n\n```\n<!-- include: nothing.md -->\n```\n"
+	if result != expected {
+		t.Errorf("ResolveIncludes() = %q, want %q", result, expected)
+	}
+	if len(included) != 2 || !strings.HasSuffix(included[0], "intro.md") || !strings.HasSuffix(included[1], "nested.md") {
+		t.Errorf("unexpected included files %v", included)
+	}
+}
+
+func TestResolveIncludesCycle(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.md": "<!-- include: b.md -->\n",
+		"b.md": "<!-- include: a.md -->\n",
+	})
+
+	deck := filepath.Join(dir, "a.md")
+	_, _, err := ResolveIncludes("<!-- include: b.md -->\n", deck)
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("expected include cycle error, got %v", err)
+	}
+}
+
+func TestResolveIncludesMissing(t *testing.T) {
+	_, included, err := ResolveIncludes("<!-- include: missing.md -->\n", filepath.Join(t.TempDir(), "deck.md"))
+	if err == nil {
+		t.Error("expected error for missing include")
+	}
+	// the missing file is watched
+	if len(included) != 1 || !strings.HasSuffix(included[0], "missing.md") {
+		t.Errorf("unexpected included files %v", included)
+	}
+}
+
+func TestResolveIncludesSeparator(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"part.md": "---\n# First\n---\n# Second\n",
+	})
+
+	result, _, err := ResolveIncludes("# Deck\n<!-- include: part.md -->\n", filepath.Join(dir, "deck.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "# Deck\n---\n# First\n---\n# Second\n"; result != expected {
+		t.Errorf("ResolveIncludes() = %q, want %q", result, expected)
+	}
+}
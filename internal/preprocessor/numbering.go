@@ -0,0 +1,136 @@
+package preprocessor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// UnnumberedClass is the class of headings which are not numbered, e.g.
+//
+//	## Agenda {.unnumbered}
+const UnnumberedClass = "unnumbered"
+
+// NumberingDepth is the deepest heading level which is numbered.
+const NumberingDepth = 3
+
+// numberedHeading is a heading with its hierarchical number.
+type numberedHeading struct {
+	node  *ast.Heading
+	slide int
+	// depth is the level relative to the shallowest heading
+	depth   int
+	section string
+	// repeated is set if the heading repeats the current heading of its
+	// level, it continues that section and has the same number
+	repeated bool
+}
+
+// numberHeadings numbers the headings of the folien up to the given depth
+// hierarchically (1, 1.1, 1.2, 2, ...), starting at the shallowest level
+// found. Headings with the unnumbered class are not numbered and do not
+// count, the generated table of contents is skipped.
+func numberHeadings(folien []string, depth int) []numberedHeading {
+	depth = min(max(depth, 1), NumberingDepth)
+
+	var headings []numberedHeading
+	minLevel := NumberingDepth
+	for i, slide := range folien {
+		if IsTOC(slide) {
+			continue
+		}
+		for _, h := range headingNodes([]byte(slide), depth) {
+			headings = append(headings, numberedHeading{node: h, slide: i})
+			minLevel = min(minLevel, h.Level)
+		}
+	}
+
+	var (
+		counters [NumberingDepth]int
+		current  [NumberingDepth]string
+		sections [NumberingDepth]string
+	)
+	for i := range headings {
+		h := &headings[i]
+		h.depth = h.node.Level - minLevel
+		title := headingText(h.node, []byte(folien[h.slide]))
+
+		if title == current[h.depth] {
+			h.repeated = true
+			h.section = sections[h.depth]
+			continue
+		}
+		current[h.depth] = title
+		for deeper := h.depth + 1; deeper < NumberingDepth; deeper++ {
+			current[deeper], sections[deeper] = "", ""
+		}
+
+		if hasClass(h.node, UnnumberedClass) {
+			sections[h.depth] = ""
+			continue
+		}
+		counters[h.depth]++
+		for deeper := h.depth + 1; deeper < NumberingDepth; deeper++ {
+			counters[deeper] = 0
+		}
+
+		numbers := make([]string, h.depth+1)
+		for level := range numbers {
+			numbers[level] = fmt.Sprint(counters[level])
+		}
+		h.section = strings.Join(numbers, ".")
+		sections[h.depth] = h.section
+	}
+	return headings
+}
+
+// NumberHeadings prefixes the headings up to the given depth with their
+// hierarchical number, e.g. "## 2.3 Consistency". Headings can be excluded
+// with the unnumbered class ({.unnumbered}), heading attributes are removed
+// as they would be displayed otherwise.
+func NumberHeadings(folien []string, depth int) []string {
+	result := slices.Clone(folien)
+
+	headings := numberHeadings(folien, depth)
+	// edit the headings from the end so that the offsets stay valid
+	for i := len(headings) - 1; i >= 0; i-- {
+		h := headings[i]
+		lines := h.node.Lines()
+		if lines.Len() == 0 {
+			continue
+		}
+
+		slide := result[h.slide]
+		if h.node.Attributes() != nil {
+			// the attributes follow the text on the last line
+			stop := lines.At(lines.Len() - 1).Stop
+			end := strings.IndexByte(slide[stop:], '\n')
+			if end < 0 {
+				end = len(slide) - stop
+			}
+			slide = strings.TrimRight(slide[:stop], " ") + slide[stop+end:]
+		}
+		if h.section != "" {
+			start := lines.At(0).Start
+			slide = slide[:start] + h.section + " " + slide[start:]
+		}
+		result[h.slide] = slide
+	}
+	return result
+}
+
+// headingText returns the text of the heading without its attributes.
+func headingText(h *ast.Heading, source []byte) string {
+	return strings.TrimSpace(string(h.Lines().Value(source)))
+}
+
+func hasClass(node ast.Node, class string) bool {
+	value, ok := node.AttributeString("class")
+	if !ok {
+		return false
+	}
+	classes, _ := value.([]byte)
+	return slices.Contains(strings.Fields(string(classes)), class)
+}
@@ -0,0 +1,52 @@
+package preprocessor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNumberHeadings(t *testing.T) {
+	folien := []string{
+		"# Introduction",
+		"## Agenda {.unnumbered}\n\n- Consistency",
+		"## Motivation\n\n```bash\n# not a heading\n```",
+		"# Replication\n\n## Leaders\n\n### Failover {#failover}",
+		"## Consistency\nContent",
+		"## Consistency\nContinued",
+		"Summary\n=======",
+	}
+
+	expected := []string{
+		"# 1 Introduction",
+		"## Agenda\n\n- Consistency",
+		"## 1.1 Motivation\n\n```bash\n# not a heading\n```",
+		"# 2 Replication\n\n## 2.1 Leaders\n\n### 2.1.1 Failover",
+		"## 2.2 Consistency\nContent",
+		"## 2.2 Consistency\nContinued",
+		"3 Summary\n=======",
+	}
+
+	result := NumberHeadings(folien, NumberingDepth)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("NumberHeadings() = %q, want %q", result, expected)
+	}
+}
+
+func TestNumberHeadingsTOC(t *testing.T) {
+	folien := []string{"# Intro", "## Agenda {.unnumbered}", "## Goals", "# Outro"}
+
+	result, err := NewConfig().WithNumbering().WithTOC("Contents", "").WithTOCOptions(TOCOptions{Depth: 2, SectionNumbers: true}).Process(folien)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"<!-- toc -->\n# Contents\n\n- 1 Intro\n  - Agenda\n  - 1.1 Goals\n- 2 Outro\n",
+		"# 1 Intro",
+		"## Agenda",
+		"## 1.1 Goals",
+		"# 2 Outro",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Process() = %q, want %q", result, expected)
+	}
+}
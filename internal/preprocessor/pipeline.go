@@ -0,0 +1,142 @@
+package preprocessor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Stage is a step of the preprocessor pipeline, it transforms the folien of
+// the presentation.
+type Stage interface {
+	Process(folien []string, c *Config) ([]string, error)
+}
+
+// StageFunc adapts a function to the Stage interface.
+type StageFunc func(folien []string, c *Config) ([]string, error)
+
+// Process calls f.
+func (f StageFunc) Process(folien []string, c *Config) ([]string, error) {
+	return f(folien, c)
+}
+
+// filterPrefix marks the stages of the pipeline which are external filters,
+// e.g. "filter:./filters/logo.py --position top".
+const filterPrefix = "filter:"
+
+// DefaultPipeline is the order of the built-in stages, stages which are not
+// enabled leave the folien unchanged.
+var DefaultPipeline = []string{"numbering", "headings", "footnotes", "citations", "toc", "templates"}
+
+// Stages are the built-in stages by their name.
+var Stages = map[string]Stage{
+	"numbering": StageFunc(numberingStage),
+	"headings":  StageFunc(headingsStage),
+	"footnotes": StageFunc(footnotesStage),
+	"citations": StageFunc(citationsStage),
+	"toc":       StageFunc(tocStage),
+	"templates": StageFunc(templatesStage),
+}
+
+// pipeline returns the stages to run. The pipeline from the command line takes
+// precedence over the one from the frontmatter, filters from the command line
+// are appended. External filters from the frontmatter are only run if
+// execution is allowed.
+func (c *Config) pipeline() ([]Stage, error) {
+	names := DefaultPipeline
+	fromMeta := false
+	switch {
+	case len(c.Pipeline) > 0:
+		names = c.Pipeline
+	case c.Meta != nil && len(c.Meta.Pipeline) > 0:
+		names, fromMeta = c.Meta.Pipeline, true
+	}
+	names = slices.Concat(names, prefixFilters(c.Filters))
+
+	stages := make([]Stage, 0, len(names))
+	for i, name := range names {
+		if command, ok := strings.CutPrefix(name, filterPrefix); ok {
+			if fromMeta && i < len(names)-len(c.Filters) && !c.AllowExecution {
+				return nil, fmt.Errorf("the filter %q of the frontmatter requires execution to be allowed", command)
+			}
+			stages = append(stages, Filter{Command: command, Dir: c.BaseDir})
+			continue
+		}
+
+		stage, ok := Stages[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown preprocessor stage %q", name)
+		}
+		stages = append(stages, stage)
+	}
+	return stages, nil
+}
+
+func prefixFilters(commands []string) []string {
+	filters := make([]string, len(commands))
+	for i, command := range commands {
+		filters[i] = filterPrefix + command
+	}
+	return filters
+}
+
+func numberingStage(folien []string, c *Config) ([]string, error) {
+	if !c.Numbering() {
+		return folien, nil
+	}
+	return NumberHeadings(folien, NumberingDepth), nil
+}
+
+func headingsStage(folien []string, c *Config) ([]string, error) {
+	depth, mode, ok := c.headings()
+	switch {
+	case !ok:
+		return folien, nil
+	case mode == HeadingsBreadcrumb:
+		return AddBreadcrumbs(folien, depth), nil
+	default:
+		return AddHeadings(folien, depth), nil
+	}
+}
+
+func footnotesStage(folien []string, _ *Config) ([]string, error) {
+	return AddFootnotes(folien), nil
+}
+
+func citationsStage(folien []string, c *Config) ([]string, error) {
+	path := c.bibliography()
+	if path == "" {
+		return folien, nil
+	}
+	bibliography, err := LoadBibliography(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not load bibliography %s: %w", c.Meta.Bibliography, err)
+	}
+	return Cite(folien, bibliography), nil
+}
+
+func tocStage(folien []string, c *Config) ([]string, error) {
+	if c.TOCTitle == "" {
+		return folien, nil
+	}
+	options := c.TOC
+	if c.Numbering() {
+		// the headings already contain their numbers
+		options.SectionNumbers = false
+	}
+	toc, folien := markTOCTargets(folien, c.TOCTitle, c.TOCDescription, options)
+	return append([]string{tocMarker + toc}, folien...), nil
+}
+
+// tocMarker marks the generated table of contents, which is skipped by the
+// other stages.
+const tocMarker = "<!-- toc -->\n"
+
+// IsTOC returns whether the slide is the generated table of contents.
+func IsTOC(slide string) bool {
+	return strings.HasPrefix(slide, tocMarker)
+}
+
+func templatesStage(folien []string, c *Config) ([]string, error) {
+	return ExecuteTemplates(folien, c.templateData(folien)), nil
+}
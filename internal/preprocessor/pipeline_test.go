@@ -0,0 +1,139 @@
+package preprocessor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/c0rydoras/folien/internal/meta"
+)
+
+func TestPipeline(t *testing.T) {
+	folien := []string{"# A", "## B"}
+
+	tests := []struct {
+		name     string
+		config   *Config
+		expected []string
+	}{
+		{
+			name:     "Default pipeline",
+			config:   NewConfig().WithHeadings().WithTOC("Contents", ""),
+			expected: []string{"<!-- toc -->\n# Contents\n\n- A\n", "# A", "# A\n## B"},
+		},
+		{
+			name:     "Custom order",
+			config:   NewConfig().WithHeadings().WithNumbering().WithPipeline([]string{"headings", "numbering"}, nil),
+			expected: []string{"# 1 A", "# 1 A\n## 1.1 B"},
+		},
+		{
+			name:     "Stages not in the pipeline are skipped",
+			config:   NewConfig().WithHeadings().WithTOC("Contents", "").WithPipeline([]string{"toc"}, nil),
+			expected: []string{"<!-- toc -->\n# Contents\n\n- A\n", "# A", "## B"},
+		},
+		{
+			name:     "Pipeline from frontmatter",
+			config:   NewConfig().WithNumbering().WithMeta(&meta.Meta{Pipeline: []string{"templates"}}),
+			expected: folien,
+		},
+		{
+			name:     "Identity filter",
+			config:   NewConfig().WithPipeline(nil, []string{"cat"}),
+			expected: folien,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.config.Process(folien)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Process() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\ngrep -q '\"customer\":\"ACME\"' && echo '{\"folien\": [\"# ACME\", \"# Thanks\"]}'\n"
+	if err := os.WriteFile(filepath.Join(dir, "filter.sh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	config := NewConfig().WithBaseDir(dir).WithExecution().WithMeta(&meta.Meta{
+		Vars:     map[string]any{"customer": "ACME"},
+		Pipeline: []string{"filter:./filter.sh"},
+	})
+	result, err := config.Process([]string{"# Customer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"# ACME", "# Thanks"}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Process() = %q, want %q", result, expected)
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		err    string
+	}{
+		{
+			name:   "Unknown stage",
+			config: NewConfig().WithPipeline([]string{"numbering", "emoji"}, nil),
+			err:    `unknown preprocessor stage "emoji"`,
+		},
+		{
+			name:   "Failing filter",
+			config: NewConfig().WithPipeline(nil, []string{"false"}),
+			err:    "filter false failed",
+		},
+		{
+			name:   "Invalid output",
+			config: NewConfig().WithPipeline(nil, []string{"echo folien"}),
+			err:    "filter echo returned invalid JSON",
+		},
+		{
+			name:   "Filter from frontmatter without execution",
+			config: NewConfig().WithMeta(&meta.Meta{Pipeline: []string{"filter:cat"}}),
+			err:    "requires execution to be allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.config.Process([]string{"# A"})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Process() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestTOCSlideNumbersAfterFilters(t *testing.T) {
+	dir := t.TempDir()
+	// inserts a title slide before the table of contents
+	script := "#!/bin/sh\nsed 's/\"folien\":\\[/\"folien\":[\"# Title\",/'\n"
+	if err := os.WriteFile(filepath.Join(dir, "title.sh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	config := NewConfig().WithBaseDir(dir).
+		WithTOC("Contents", "").
+		WithTOCOptions(TOCOptions{SlideNumbers: true}).
+		WithPipeline([]string{"toc", "filter:./title.sh"}, nil)
+	result, err := config.Process([]string{"# A", "# B\n\n```bash\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"# Title", "<!-- toc -->\n# Contents\n\n- A · 3\n- B · 4\n", "# A", "# B\n\n```bash\n"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Process() = %q, want %q", result, expected)
+	}
+}
@@ -0,0 +1,296 @@
+package preprocessor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/c0rydoras/folien/internal/code"
+	mdparser "github.com/c0rydoras/folien/pkg/parser"
+	"github.com/c0rydoras/folien/pkg/util"
+)
+
+// snippetAttributes are the attributes selecting the imported code, they are
+// removed from the info string of the imported block.
+var snippetAttributes = []string{"file", "lines", "region", "symbol"}
+
+// regionRegexp matches region markers in line comments of most languages:
+//
+//	// region setup
+//	# endregion
+var regionRegexp = regexp.MustCompile(`^\s*(?://|#|--|;)\s*(region|endregion)\b\s*(.*?)\s*$`)
+
+// ImportSnippets fills code blocks with a file attribute with the content of
+// that file. The imported part can be narrowed down with one of the
+// attributes lines (10-40, 10- or 10), region (the lines between
+// "// region name" and "// endregion") or, for Go files, symbol (Name or
+// Type.Method). Paths are relative to dir. If the block has no language it is
+// inferred from the extension of the file. Tables are left to ImportTables.
+// It returns the content and all imported files.
+func ImportSnippets(content string, dir string) (string, []string, error) {
+	var (
+		imported []string
+		err      error
+	)
+
+	content = code.ReplaceBlocks(content, func(_ int, block code.Block) (string, bool) {
+		file, ok := block.Attributes["file"]
+		if !ok || block.Language == TableLanguage || err != nil {
+			return "", false
+		}
+
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		var snippet string
+		snippet, err = importSnippet(path, block.Attributes)
+		if err != nil {
+			err = fmt.Errorf("could not import %s: %w", file, err)
+			return "", false
+		}
+		if abs, absErr := filepath.Abs(path); absErr == nil && !slices.Contains(imported, abs) {
+			imported = append(imported, abs)
+		}
+
+		language := block.Language
+		if language == "" {
+			language = code.LanguageFromExtension(strings.TrimPrefix(filepath.Ext(path), "."))
+		}
+		attributes := map[string]string{}
+		for key, value := range block.Attributes {
+			if !slices.Contains(snippetAttributes, key) {
+				attributes[key] = value
+			}
+		}
+		return code.Fence(mdparser.FormatInfo(language, attributes), snippet), true
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	// imported blocks occur in reverse order
+	slices.Reverse(imported)
+	return content, imported, nil
+}
+
+func importSnippet(path string, attributes map[string]string) (string, error) {
+	data, err := util.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	data = strings.ReplaceAll(data, "\r", "")
+
+	switch {
+	case attributes["lines"] != "":
+		return selectLines(data, attributes["lines"])
+	case attributes["region"] != "":
+		return selectRegion(data, attributes["region"])
+	case attributes["symbol"] != "":
+		return selectSymbol(path, data, attributes["symbol"])
+	default:
+		return data, nil
+	}
+}
+
+// selectLines returns the lines of the given range, both ends are included
+// and counted from 1.
+func selectLines(data string, lineRange string) (string, error) {
+	lines := strings.SplitAfter(strings.TrimSuffix(data, "\n"), "\n")
+
+	from, to, isRange := strings.Cut(lineRange, "-")
+	start, err := strconv.Atoi(from)
+	if err != nil || start < 1 {
+		return "", fmt.Errorf("invalid line range %q", lineRange)
+	}
+	stop := start
+	if isRange {
+		stop = len(lines)
+		if to != "" {
+			stop, err = strconv.Atoi(to)
+			if err != nil || stop < start {
+				return "", fmt.Errorf("invalid line range %q", lineRange)
+			}
+		}
+	}
+	if start > len(lines) {
+		return "", fmt.Errorf("line %d is out of range, the file has %d lines", start, len(lines))
+	}
+
+	return strings.Join(lines[start-1:min(stop, len(lines))], ""), nil
+}
+
+// selectRegion returns the dedented lines between the markers of the region
+// with the given name, markers of other regions inside it are removed.
+func selectRegion(data string, name string) (string, error) {
+	var (
+		region []string
+		inside bool
+		depth  int
+	)
+
+	for _, line := range strings.Split(data, "\n") {
+		matches := regionRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			if inside {
+				region = append(region, line)
+			}
+			continue
+		}
+
+		switch {
+		case !inside && matches[1] == "region" && matches[2] == name:
+			inside = true
+		case inside && matches[1] == "region":
+			depth++
+		case inside && depth > 0:
+			depth--
+		case inside:
+			return dedent(region), nil
+		}
+	}
+
+	if inside {
+		return "", fmt.Errorf("region %q is not closed", name)
+	}
+	return "", fmt.Errorf("region %q not found", name)
+}
+
+// dedent removes the common indentation of the given lines.
+func dedent(lines []string) string {
+	indent := ""
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			indent, first = lineIndent, false
+			continue
+		}
+		for !strings.HasPrefix(lineIndent, indent) {
+			indent = indent[:len(indent)-1]
+		}
+	}
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(strings.TrimPrefix(line, indent))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// selectSymbol returns the declaration of a Go symbol including its doc
+// comment. Functions, types, variables and constants are looked up by their
+// name, methods as Type.Method.
+func selectSymbol(path string, data string, symbol string) (string, error) {
+	if filepath.Ext(path) != ".go" {
+		return "", fmt.Errorf("symbol lookup is only supported for Go files")
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, data, parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+
+	source := func(from, to token.Pos) string {
+		return data[fset.Position(from).Offset:fset.Position(to).Offset] + "\n"
+	}
+	receiver, name, isMethod := strings.Cut(symbol, ".")
+	if !isMethod {
+		name, receiver = receiver, ""
+	}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Name.Name != name || receiverName(decl) != receiver {
+				continue
+			}
+			start := decl.Pos()
+			if decl.Doc != nil {
+				start = decl.Doc.Pos()
+			}
+			return source(start, decl.End()), nil
+		case *ast.GenDecl:
+			if isMethod {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				if !specDeclares(spec, name) {
+					continue
+				}
+				// ungrouped declarations are returned as a whole
+				if !decl.Lparen.IsValid() {
+					start := decl.Pos()
+					if decl.Doc != nil {
+						start = decl.Doc.Pos()
+					}
+					return source(start, decl.End()), nil
+				}
+				var b strings.Builder
+				if doc := specDoc(spec); doc != nil {
+					b.WriteString(source(doc.Pos(), doc.End()))
+				}
+				b.WriteString(decl.Tok.String() + " " + source(spec.Pos(), spec.End()))
+				return b.String(), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("symbol %q not found", symbol)
+}
+
+// receiverName returns the name of the receiver type of a method, or an empty
+// string for functions.
+func receiverName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return ""
+	}
+	typ := decl.Recv.List[0].Type
+	for {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+		case *ast.IndexExpr:
+			typ = t.X
+		case *ast.IndexListExpr:
+			typ = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func specDeclares(spec ast.Spec, name string) bool {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		return spec.Name.Name == name
+	case *ast.ValueSpec:
+		return slices.ContainsFunc(spec.Names, func(ident *ast.Ident) bool {
+			return ident.Name == name
+		})
+	}
+	return false
+}
+
+func specDoc(spec ast.Spec) *ast.CommentGroup {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		return spec.Doc
+	case *ast.ValueSpec:
+		return spec.Doc
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package preprocessor
+
+import (
+	"strings"
+	"testing"
+)
+
+const snippetSource = `package server
+
+import "net/http"
+
+const (
+	// Port is the default port.
+	Port = 8080
+	Host = "localhost"
+)
+
+// Handler serves requests.
+type Handler struct{}
+
+// ServeHTTP answers every request.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// region body
+	w.WriteHeader(http.StatusOK)
+	// region inner
+	_, _ = w.Write([]byte("ok"))
+	// endregion
+	// endregion
+}
+`
+
+func TestImportSnippets(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"server/handler.go": snippetSource,
+		"config.yaml":       "port: 8080\n",
+	})
+
+	tests := []struct {
+		name     string
+		block    string
+		expected string
+	}{
+		{
+			name:     "Whole file with inferred language",
+			block:    "```file=config.yaml\n```\n",
+			expected: "```yaml\nport: 8080\n```\n",
+		},
+		{
+			name:     "Line range",
+			block:    "```go file=server/handler.go lines=1-3\n```\n",
+			expected: "```go\npackage server\n\nimport \"net/http\"\n```\n",
+		},
+		{
+			name:     "Single line",
+			block:    "```file=server/handler.go lines=3\n```\n",
+			expected: "```go\nimport \"net/http\"\n```\n",
+		},
+		{
+			name:     "Open line range",
+			block:    "```file=server/handler.go lines=21-\n```\n",
+			expected: "```go\n\t// endregion\n}\n```\n",
+		},
+		{
+			name:     "Region",
+			block:    "```file=server/handler.go region=body\n```\n",
+			expected: "```go\nw.WriteHeader(http.StatusOK)\n_, _ = w.Write([]byte(\"ok\"))\n```\n",
+		},
+		{
+			name:     "Method",
+			block:    "```go file=server/handler.go symbol=Handler.ServeHTTP name=serve\n```\n",
+			expected: "```go name=serve\n// ServeHTTP answers every request.\nfunc (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {\n\t// region body\n\tw.WriteHeader(http.StatusOK)\n\t// region inner\n\t_, _ = w.Write([]byte(\"ok\"))\n\t// endregion\n\t// endregion\n}\n```\n",
+		},
+		{
+			name:     "Type",
+			block:    "```file=server/handler.go symbol=Handler\n```\n",
+			expected: "```go\n// Handler serves requests.\ntype Handler struct{}\n```\n",
+		},
+		{
+			name:     "Grouped constant",
+			block:    "```file=server/handler.go symbol=Port\n```\n",
+			expected: "```go\n// Port is the default port.\nconst Port = 8080\n```\n",
+		},
+		{
+			name:     "Block without file",
+			block:    "```go\nfmt.Println()\n```\n",
+			expected: "```go\nfmt.Println()\n```\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := ImportSnippets("# Slide\n\n"+tt.block, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := "# Slide\n\n" + tt.expected; result != expected {
+				t.Errorf("ImportSnippets() = %q, want %q", result, expected)
+			}
+		})
+	}
+}
+
+func TestImportSnippetsErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"handler.go": snippetSource,
+		"script.sh":  "echo hi\n",
+	})
+
+	tests := []struct {
+		block string
+		err   string
+	}{
+		{"```file=missing.go\n```\n", "could not import missing.go"},
+		{"```file=handler.go lines=30-40\n```\n", "out of range"},
+		{"```file=handler.go lines=5-2\n```\n", "invalid line range"},
+		{"```file=handler.go region=missing\n```\n", `region "missing" not found`},
+		{"```file=handler.go symbol=Handler.Missing\n```\n", `symbol "Handler.Missing" not found`},
+		{"```file=script.sh symbol=main\n```\n", "only supported for Go files"},
+	}
+
+	for _, tt := range tests {
+		_, _, err := ImportSnippets(tt.block, dir)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ImportSnippets(%q) error = %v, want %q", tt.block, err, tt.err)
+		}
+	}
+}
+
+func TestImportSnippetsInIncludes(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"deck.md":           "<!-- include: parts/code.md -->\n",
+		"parts/code.md":     "```file=src/main.sh\n```\n",
+		"parts/src/main.sh": "echo hi\n",
+	})
+
+	result, included, err := ResolveIncludes("<!-- include: parts/code.md -->\n", dir+"/deck.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "```bash\necho hi\n```\n"; result != expected {
+		t.Errorf("ResolveIncludes() = %q, want %q", result, expected)
+	}
+	if len(included) != 2 || !strings.HasSuffix(included[1], "main.sh") {
+		t.Errorf("unexpected included files %v", included)
+	}
+}
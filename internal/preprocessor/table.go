@@ -0,0 +1,331 @@
+package preprocessor
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/c0rydoras/folien/internal/code"
+	"github.com/c0rydoras/folien/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// TableLanguage is the language of code blocks which are turned into
+// markdown tables.
+const TableLanguage = "table"
+
+// table is the data of a table, every row has a value for each column.
+type table struct {
+	columns []string
+	rows    [][]string
+}
+
+// ImportTables turns code blocks with the table language into markdown
+// tables. The data is read from the file given by the file attribute or from
+// the content of the block, the format (csv, tsv, json or yaml) is inferred
+// from the extension or given by the format attribute. The attributes columns
+// (name,p50), sort (p50, or -p50 for descending order) and limit select the
+// displayed data. Paths are relative to dir. It returns the content and all
+// imported files.
+func ImportTables(content string, dir string) (string, []string, error) {
+	var (
+		imported []string
+		err      error
+	)
+
+	content = code.ReplaceBlocks(content, func(_ int, block code.Block) (string, bool) {
+		if block.Language != TableLanguage || err != nil {
+			return "", false
+		}
+
+		data, format := block.Code, block.Attributes["format"]
+		if file, ok := block.Attributes["file"]; ok {
+			path := file
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			data, err = util.ReadFile(path)
+			if err != nil {
+				err = fmt.Errorf("could not import table %s: %w", file, err)
+				return "", false
+			}
+			if abs, absErr := filepath.Abs(path); absErr == nil && !slices.Contains(imported, abs) {
+				imported = append(imported, abs)
+			}
+			if format == "" {
+				format = strings.TrimPrefix(filepath.Ext(path), ".")
+			}
+		}
+
+		var markdown string
+		markdown, err = renderTable(data, format, block.Attributes)
+		if err != nil {
+			if file, ok := block.Attributes["file"]; ok {
+				err = fmt.Errorf("could not import table %s: %w", file, err)
+			} else {
+				err = fmt.Errorf("invalid table: %w", err)
+			}
+			return "", false
+		}
+		// the table is surrounded by blank lines so that it is neither
+		// merged into a paragraph before nor followed by a lazy continuation
+		return "\n" + markdown + "\n", true
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	// imported blocks occur in reverse order
+	slices.Reverse(imported)
+	return content, imported, nil
+}
+
+func renderTable(data, format string, attributes map[string]string) (string, error) {
+	data = strings.ReplaceAll(data, "\r", "")
+
+	var (
+		t   table
+		err error
+	)
+	switch strings.ToLower(format) {
+	case "", "csv":
+		t, err = parseCSV(data, ',')
+	case "tsv":
+		t, err = parseCSV(data, '\t')
+	case "json", "yaml", "yml":
+		// JSON is valid YAML, the YAML parser keeps the order of the keys
+		t, err = parseYAML(data)
+	default:
+		return "", fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if columns := attributes["columns"]; columns != "" {
+		if t, err = t.selectColumns(strings.Split(columns, ",")); err != nil {
+			return "", err
+		}
+	}
+	// a table without columns is not valid markdown
+	if len(t.columns) == 0 {
+		return "", fmt.Errorf("no columns")
+	}
+	if column := attributes["sort"]; column != "" {
+		if err = t.sort(column); err != nil {
+			return "", err
+		}
+	}
+	if limit := attributes["limit"]; limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid limit %q", limit)
+		}
+		t.rows = t.rows[:min(n, len(t.rows))]
+	}
+	return t.markdown(), nil
+}
+
+// parseCSV parses comma or tab separated values, the first record contains
+// the names of the columns.
+func parseCSV(data string, separator rune) (table, error) {
+	r := csv.NewReader(strings.NewReader(data))
+	r.Comma = separator
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return table{}, err
+	}
+	if len(records) == 0 {
+		return table{}, fmt.Errorf("no columns")
+	}
+
+	t := table{columns: records[0]}
+	for _, record := range records[1:] {
+		row := make([]string, len(t.columns))
+		copy(row, record)
+		t.rows = append(t.rows, row)
+	}
+	return t, nil
+}
+
+// parseYAML parses a list of objects, the columns are the keys of the
+// objects in the order of their first occurrence.
+func parseYAML(data string) (table, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &document); err != nil {
+		return table{}, err
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.SequenceNode {
+		return table{}, fmt.Errorf("expected a list of objects")
+	}
+
+	var (
+		t       table
+		objects []map[string]string
+	)
+	for _, item := range document.Content[0].Content {
+		if item.Kind != yaml.MappingNode {
+			return table{}, fmt.Errorf("expected a list of objects")
+		}
+		object := map[string]string{}
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			key := item.Content[i].Value
+			if !slices.Contains(t.columns, key) {
+				t.columns = append(t.columns, key)
+			}
+			object[key] = nodeValue(item.Content[i+1])
+		}
+		objects = append(objects, object)
+	}
+
+	for _, object := range objects {
+		row := make([]string, len(t.columns))
+		for i, column := range t.columns {
+			row[i] = object[column]
+		}
+		t.rows = append(t.rows, row)
+	}
+	return t, nil
+}
+
+// nodeValue returns the value of scalars, other values are formatted as
+// JSON.
+func nodeValue(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		if node.Tag == "!!null" {
+			return ""
+		}
+		return node.Value
+	}
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return node.Value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func (t table) column(name string) (int, error) {
+	i := slices.Index(t.columns, strings.TrimSpace(name))
+	if i < 0 {
+		return 0, fmt.Errorf("unknown column %q, the columns are %s", strings.TrimSpace(name), strings.Join(t.columns, ", "))
+	}
+	return i, nil
+}
+
+func (t table) selectColumns(names []string) (table, error) {
+	indices := make([]int, len(names))
+	for i, name := range names {
+		var err error
+		if indices[i], err = t.column(name); err != nil {
+			return table{}, err
+		}
+	}
+
+	selected := table{columns: make([]string, len(names))}
+	for i, index := range indices {
+		selected.columns[i] = t.columns[index]
+	}
+	for _, row := range t.rows {
+		values := make([]string, len(indices))
+		for i, index := range indices {
+			values[i] = row[index]
+		}
+		selected.rows = append(selected.rows, values)
+	}
+	return selected, nil
+}
+
+// sort sorts the rows by the given column, numbers are compared by their
+// value. A leading minus sorts in descending order.
+func (t table) sort(column string) error {
+	name, descending := strings.CutPrefix(column, "-")
+	i, err := t.column(name)
+	if err != nil {
+		return err
+	}
+
+	slices.SortStableFunc(t.rows, func(a, b []string) int {
+		result := compareValues(a[i], b[i])
+		if descending {
+			return -result
+		}
+		return result
+	})
+	return nil
+}
+
+func compareValues(a, b string) int {
+	x, aIsNumber := parseNumber(a)
+	y, bIsNumber := parseNumber(b)
+	if aIsNumber && bIsNumber {
+		return cmp.Compare(x, y)
+	}
+	return strings.Compare(a, b)
+}
+
+// parseNumber parses numbers, percentages are supported.
+func parseNumber(value string) (float64, bool) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "%")
+	number, err := strconv.ParseFloat(value, 64)
+	return number, err == nil
+}
+
+// markdown formats the table, columns containing only numbers are aligned
+// to the right.
+func (t table) markdown() string {
+	var b strings.Builder
+	writeRow := func(values []string) {
+		b.WriteString("|")
+		for _, value := range values {
+			b.WriteString(" " + escapeCell(value) + " |")
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(t.columns)
+	b.WriteString("|")
+	for i := range t.columns {
+		if t.numeric(i) {
+			b.WriteString(" ---: |")
+		} else {
+			b.WriteString(" --- |")
+		}
+	}
+	b.WriteString("\n")
+	for _, row := range t.rows {
+		writeRow(row)
+	}
+	return b.String()
+}
+
+// numeric returns whether all values of the column, which are not empty, are
+// numbers.
+func (t table) numeric(column int) bool {
+	found := false
+	for _, row := range t.rows {
+		if strings.TrimSpace(row[column]) == "" {
+			continue
+		}
+		if _, ok := parseNumber(row[column]); !ok {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+func escapeCell(value string) string {
+	value = strings.ReplaceAll(strings.TrimSpace(value), "\n", " ")
+	return strings.ReplaceAll(value, "|", `\|`)
+}
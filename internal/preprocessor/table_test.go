@@ -0,0 +1,138 @@
+package preprocessor
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportTables(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"results.csv": "name,p50,p99\nparse,12.5,40\nrender,3,9\n\"load, cold\",120,300\n",
+		"results.tsv": "name\tp50\nparse\t12.5\n",
+		"data/results.json": `[{"name": "parse", "p50": 12.5, "tags": ["fast"]},
+			{"name": "render", "p50": 3, "cached": true}]`,
+		"results.yaml": "- name: parse\n  owner: a|b\n- name: render\n  owner: null\n",
+	})
+
+	tests := []struct {
+		name     string
+		block    string
+		expected string
+	}{
+		{
+			name:  "CSV",
+			block: "```table file=results.csv\n```",
+			expected: "\n| name | p50 | p99 |\n| --- | ---: | ---: |\n" +
+				"| parse | 12.5 | 40 |\n| render | 3 | 9 |\n| load, cold | 120 | 300 |\n\n",
+		},
+		{
+			name:     "Columns, sort and limit",
+			block:    "```table file=results.csv columns=name,p50 sort=-p50 limit=2\n```",
+			expected: "\n| name | p50 |\n| --- | ---: |\n| load, cold | 120 |\n| parse | 12.5 |\n\n",
+		},
+		{
+			name:     "Ascending sort by text",
+			block:    "```table file=results.csv columns=name sort=name\n```",
+			expected: "\n| name |\n| --- |\n| load, cold |\n| parse |\n| render |\n\n",
+		},
+		{
+			name:     "TSV",
+			block:    "```table file=results.tsv\n```",
+			expected: "\n| name | p50 |\n| --- | ---: |\n| parse | 12.5 |\n\n",
+		},
+		{
+			name:  "JSON keeps the order of the keys",
+			block: "```table file=data/results.json\n```",
+			expected: "\n| name | p50 | tags | cached |\n| --- | ---: | --- | --- |\n" +
+				"| parse | 12.5 | [\"fast\"] |  |\n| render | 3 |  | true |\n\n",
+		},
+		{
+			name:     "YAML",
+			block:    "```table file=results.yaml\n```",
+			expected: "\n| name | owner |\n| --- | --- |\n| parse | a\\|b |\n| render |  |\n\n",
+		},
+		{
+			name:     "Inline data",
+			block:    "```table format=yaml\n- a: 1\n```",
+			expected: "\n| a |\n| ---: |\n| 1 |\n\n",
+		},
+		{
+			name:     "Blank lines around the table",
+			block:    "Results:\n```table format=csv\na\n1\n```\nin ms",
+			expected: "Results:\n\n| a |\n| ---: |\n| 1 |\n\nin ms",
+		},
+		{
+			name:     "Other blocks are kept",
+			block:    "```csv file=results.csv\n```",
+			expected: "```csv file=results.csv\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, imported, err := ImportTables(tt.block, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.expected {
+				t.Errorf("ImportTables() =\n%s\nwant\n%s", result, tt.expected)
+			}
+			if strings.Contains(tt.block, "file=") && tt.block != tt.expected && len(imported) != 1 {
+				t.Errorf("expected the file to be imported, got %v", imported)
+			}
+		})
+	}
+}
+
+func TestImportTablesErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"results.csv": "name,p50\nparse,12\n",
+		"object.json": `{"name": "parse"}`,
+	})
+
+	tests := []struct {
+		block string
+		err   string
+	}{
+		{"```table file=missing.csv\n```", "could not import table missing.csv"},
+		{"```table file=results.csv columns=name,p90\n```", `unknown column "p90"`},
+		{"```table file=results.csv sort=p90\n```", `unknown column "p90"`},
+		{"```table file=results.csv limit=ten\n```", `invalid limit "ten"`},
+		{"```table file=object.json\n```", "expected a list of objects"},
+		{"```table file=results.csv format=xml\n```", `unsupported format "xml"`},
+		{"```table format=yaml\n[]\n```", "no columns"},
+		{"```table format=json\n[{}, {}]\n```", "no columns"},
+		{"```table\n```", "no columns"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			_, _, err := ImportTables(tt.block, dir)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ImportTables() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestImportTablesOfIncludes(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"folien.md":      "<!-- include: part/tables.md -->\n",
+		"part/tables.md": "```table file=data.csv\n```\n",
+		"part/data.csv":  "a\n1\n",
+	})
+
+	content, included, err := ResolveIncludes("<!-- include: part/tables.md -->\n", filepath.Join(dir, "folien.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "\n| a |\n| ---: |\n| 1 |\n\n"; content != expected {
+		t.Errorf("ResolveIncludes() = %q, want %q", content, expected)
+	}
+	expected := []string{filepath.Join(dir, "part/tables.md"), filepath.Join(dir, "part/data.csv")}
+	if !reflect.DeepEqual(included, expected) {
+		t.Errorf("included = %v, want %v", included, expected)
+	}
+}
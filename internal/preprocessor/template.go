@@ -0,0 +1,110 @@
+package preprocessor
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/c0rydoras/folien/internal/meta"
+	"github.com/c0rydoras/folien/pkg/parser"
+)
+
+// TemplateData is the data available in the templates of a slide.
+type TemplateData struct {
+	// Title is the title of the presentation from the frontmatter, or its
+	// first heading.
+	Title  string
+	Author string
+	Date   string
+	// Slide is the number of the slide, starting at 1.
+	Slide int
+	// Slides is the number of slides.
+	Slides int
+	// Vars contains the variables from the frontmatter and the command line.
+	Vars map[string]any
+}
+
+// templateFuncs are the functions available in templates in addition to the
+// builtin functions of text/template.
+var templateFuncs = template.FuncMap{
+	// env returns the value of an environment variable
+	"env": os.Getenv,
+	// date formats the current date, e.g. {{ date "YYYY-MM-DD" }}
+	"date": func(format string) string {
+		return time.Now().Format(meta.DateLayout(format))
+	},
+	// default returns value, or fallback if value is empty
+	"default": func(fallback any, value any) any {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// skippedCode is the placeholder for code which is not templated.
+const skippedCode = "\x00code%d\x00"
+
+// ExecuteTemplates evaluates the folien as Go templates. Code spans, indented
+// code blocks and fenced code blocks are left untouched, unless fenced code
+// blocks have the template attribute. If a template fails the
+// slide is kept as is and the error is appended to it.
+func ExecuteTemplates(folien []string, data TemplateData) []string {
+	result := make([]string, len(folien))
+	for i, slide := range folien {
+		slideData := data
+		slideData.Slide, slideData.Slides = i+1, len(folien)
+
+		out, err := executeTemplate(slide, slideData)
+		if err != nil {
+			out = fmt.Sprintf("%s\n\n> Template error: %s\n", strings.TrimRight(slide, "\n"), err)
+		}
+		result[i] = out
+	}
+	return result
+}
+
+func executeTemplate(slide string, data TemplateData) (string, error) {
+	if !strings.Contains(slide, "{{") {
+		return slide, nil
+	}
+
+	// replace the code, which is not templated, with placeholders
+	source := []byte(slide)
+	var templated [][2]int
+	for _, block := range parser.CollectCodeBlocks(source) {
+		if _, ok := parser.Attributes(block, source)["template"]; ok {
+			start, stop := parser.BlockRange(block, source)
+			templated = append(templated, [2]int{start, stop})
+		}
+	}
+	ranges := slices.DeleteFunc(codeRanges(slide), func(r [2]int) bool {
+		return slices.Contains(templated, r)
+	})
+	slices.SortFunc(ranges, func(a, b [2]int) int { return b[0] - a[0] })
+
+	skipped := map[string]string{}
+	for i, r := range ranges {
+		placeholder := fmt.Sprintf(skippedCode, i)
+		skipped[placeholder] = string(source[r[0]:r[1]])
+		source = slices.Concat(source[:r[0]], []byte(placeholder), source[r[1]:])
+	}
+
+	tmpl, err := template.New("slide").Funcs(templateFuncs).Option("missingkey=error").Parse(string(source))
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	out := b.String()
+	for placeholder, block := range skipped {
+		out = strings.Replace(out, placeholder, block, 1)
+	}
+	return out, nil
+}
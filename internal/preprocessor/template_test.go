@@ -0,0 +1,88 @@
+package preprocessor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/c0rydoras/folien/internal/meta"
+)
+
+func TestExecuteTemplates(t *testing.T) {
+	t.Setenv("FOLIEN_TEST_REGION", "eu-west-1")
+
+	data := TemplateData{
+		Title: "Quarterly Review",
+		Vars:  map[string]any{"customer": "ACME"},
+	}
+
+	tests := []struct {
+		name     string
+		slide    string
+		expected string
+	}{
+		{
+			name:     "Variables and helpers",
+			slide:    "# {{ .Title }} for {{ .Vars.customer }}\n\nSlide {{ .Slide }} of {{ .Slides }}",
+			expected: "# Quarterly Review for ACME\n\nSlide 1 of 1",
+		},
+		{
+			name:     "Environment",
+			slide:    `Region: {{ env "FOLIEN_TEST_REGION" }}, zone: {{ env "FOLIEN_TEST_ZONE" | default "a" }}`,
+			expected: "Region: eu-west-1, zone: a",
+		},
+		{
+			name:     "Code blocks are skipped",
+			slide:    "{{ .Vars.customer }}\n\n```go\nfmt.Println(\"{{ .Vars.customer }}\")\n```\n",
+			expected: "ACME\n\n```go\nfmt.Println(\"{{ .Vars.customer }}\")\n```\n",
+		},
+		{
+			name:     "Code spans and indented code blocks are skipped",
+			slide:    "Set `{{ .Values.image }}` for {{ .Vars.customer }}\n\n    image: {{ .Values.image }}\n",
+			expected: "Set `{{ .Values.image }}` for ACME\n\n    image: {{ .Values.image }}\n",
+		},
+		{
+			name:     "Code blocks with the template attribute",
+			slide:    "```bash template\necho {{ .Vars.customer }}\n```\n",
+			expected: "```bash template\necho ACME\n```\n",
+		},
+		{
+			name:     "Conditional around code blocks",
+			slide:    "{{ if .Vars.customer }}\n```go\n{{ x }}\n```\n{{ end }}",
+			expected: "\n```go\n{{ x }}\n```\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ExecuteTemplates([]string{tt.slide}, data)
+			if result[0] != tt.expected {
+				t.Errorf("ExecuteTemplates() = %q, want %q", result[0], tt.expected)
+			}
+		})
+	}
+}
+
+func TestExecuteTemplatesError(t *testing.T) {
+	result := ExecuteTemplates([]string{"# {{ .Vars.missing }}"}, TemplateData{Vars: map[string]any{}})
+	if !strings.HasPrefix(result[0], "# {{ .Vars.missing }}\n\n> Template error:") {
+		t.Errorf("expected the slide with a template error, got %q", result[0])
+	}
+}
+
+func TestProcessTemplates(t *testing.T) {
+	base := NewConfig().WithTOC("Contents", "").WithVars(map[string]string{"customer": "Globex"})
+	config := base.WithMeta(&meta.Meta{Vars: map[string]any{"customer": "ACME", "seats": 12}})
+
+	result, err := config.Process([]string{"# Welcome", "{{ .Title }}: {{ .Vars.customer }}, {{ .Vars.seats }} seats, slide {{ .Slide }}/{{ .Slides }}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"<!-- toc -->\n# Contents\n\n- Welcome\n", "# Welcome", "Welcome: Globex, 12 seats, slide 3/3"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Process() = %q, want %q", result, expected)
+	}
+	if base.Meta != nil {
+		t.Error("WithMeta should return a copy of the config")
+	}
+}
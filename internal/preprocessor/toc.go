@@ -2,54 +2,186 @@ package preprocessor
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
 )
 
-func collectH1s(slideContent []byte) []string {
-	parser := goldmark.DefaultParser()
+// TOCOptions configure the generated table of contents.
+type TOCOptions struct {
+	// Depth is the deepest heading level included (1-3), defaults to 1.
+	Depth int
+	// SlideNumbers adds the number of the slide to each entry.
+	SlideNumbers bool
+	// SectionNumbers numbers the entries hierarchically (1, 1.1, 1.2, ...).
+	SectionNumbers bool
+}
+
+// TOCEntry is a heading in the table of contents.
+type TOCEntry struct {
+	Level int
+	// Depth is the level relative to the shallowest heading, starting at 0.
+	Depth int
+	Title string
+	// Slide is the index of the slide containing the heading.
+	Slide int
+	// Section is the hierarchical number of the heading, e.g. "1.2", it is
+	// empty for unnumbered headings.
+	Section string
+}
+
+// headingNodes returns the headings up to the given depth, heading attributes
+// ({#id .class}) are parsed and not part of their text.
+func headingNodes(slideContent []byte, depth int) []*ast.Heading {
+	p := goldmark.New(goldmark.WithParserOptions(parser.WithAttribute())).Parser()
 	reader := text.NewReader(slideContent)
-	doc := parser.Parse(reader)
+	doc := p.Parse(reader)
 
-	var h1s []string
+	var headings []*ast.Heading
 
 	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
 		if !entering {
 			return ast.WalkContinue, nil
 		}
 
-		if h, ok := n.(*ast.Heading); ok {
-			if h.Level == 1 {
-				h1s = append(h1s, string(h.Lines().Value(slideContent)))
-			}
+		if h, ok := n.(*ast.Heading); ok && h.Level <= depth {
+			headings = append(headings, h)
 		}
 		return ast.WalkContinue, nil
 	})
 
 	if err != nil {
-		return []string{}
+		return []*ast.Heading{}
+	}
+	return headings
+}
+
+func collectH1s(slideContent []byte) []string {
+	var h1s []string
+	for _, h := range headingNodes(slideContent, 1) {
+		h1s = append(h1s, headingText(h, slideContent))
 	}
 	return h1s
 }
 
-func GenerateTOC(folien []string, title string, description string) string {
+// CollectTOC returns the headings of the folien up to the given depth.
+// Headings repeating the current heading of their level (e.g. inherited
+// headings) are listed once.
+func CollectTOC(folien []string, depth int) []TOCEntry {
+	var entries []TOCEntry
+	for _, h := range numberHeadings(folien, depth) {
+		if h.repeated {
+			continue
+		}
+		entries = append(entries, TOCEntry{
+			Level:   h.node.Level,
+			Depth:   h.depth,
+			Title:   headingText(h.node, []byte(folien[h.slide])),
+			Slide:   h.slide,
+			Section: h.section,
+		})
+	}
+	return entries
+}
+
+// GenerateTOC returns a slide listing the headings of the folien. The slide
+// is meant to be inserted before the folien, slide numbers are shifted
+// accordingly.
+func GenerateTOC(folien []string, title string, description string, options TOCOptions) string {
+	return generateTOC(folien, title, description, options, func(slide int) string {
+		// +1 for the table of contents and +1 as slides are counted from 1
+		return fmt.Sprintf(" · %d", slide+2)
+	})
+}
+
+// generateTOC returns the table of contents, number returns the slide number
+// appended to the entries of the given slide.
+func generateTOC(folien []string, title string, description string, options TOCOptions, number func(slide int) string) string {
+	if len(folien) == 0 {
+		return ""
+	}
+
 	var toc strings.Builder
 	toc.WriteString(fmt.Sprintf("# %s\n\n", title))
 	if description != "" {
 		toc.WriteString(fmt.Sprintf("%s\n\n", description))
 	}
 
-	var headings = collectH1s([]byte(strings.Join(folien, "\n")))
+	for _, entry := range CollectTOC(folien, options.Depth) {
+		toc.WriteString(strings.Repeat("  ", entry.Depth))
+		toc.WriteString("- ")
+		if options.SectionNumbers && entry.Section != "" {
+			toc.WriteString(entry.Section + " ")
+		}
+		toc.WriteString(entry.Title)
+		if options.SlideNumbers {
+			toc.WriteString(number(entry.Slide))
+		}
+		toc.WriteString("\n")
+	}
 
-	for _, text := range headings {
-		toc.WriteString(fmt.Sprintf("- %s\n", text))
+	return toc.String()
+}
+
+var (
+	// tocTargetRegexp matches the markers of the folien listed in the table
+	// of contents, see markTOCTargets.
+	tocTargetRegexp = regexp.MustCompile(`(?:\n\n)?<!-- toc-target: (\d+) -->\n?`)
+	// tocNumberRegexp matches the placeholders of the slide numbers in the
+	// table of contents.
+	tocNumberRegexp = regexp.MustCompile(`<!-- toc-number: (\d+) -->`)
+)
+
+// markTOCTargets returns the table of contents with placeholders for the
+// slide numbers and the folien with a marker for each listed slide. The later
+// stages and filters may add, remove or reorder folien, so the numbers are
+// only resolved by numberTOC after all stages ran.
+func markTOCTargets(folien []string, title string, description string, options TOCOptions) (string, []string) {
+	if !options.SlideNumbers {
+		return GenerateTOC(folien, title, description, options), folien
 	}
 
-	if len(folien) == 0 {
-		return ""
+	marked := slices.Clone(folien)
+	toc := generateTOC(folien, title, description, options, func(slide int) string {
+		if !strings.Contains(marked[slide], fmt.Sprintf("<!-- toc-target: %d -->", slide)) {
+			marked[slide] += fmt.Sprintf("\n\n<!-- toc-target: %d -->\n", slide)
+		}
+		return fmt.Sprintf("<!-- toc-number: %d -->", slide)
+	})
+	return toc, marked
+}
+
+// numberTOC replaces the placeholders of the slide numbers in the table of
+// contents with the positions of the marked folien and removes the markers.
+// Entries of folien which were removed lose their number.
+func numberTOC(folien []string) []string {
+	folien = slices.Clone(folien)
+	positions := map[string]int{}
+	for i, slide := range folien {
+		for _, match := range tocTargetRegexp.FindAllStringSubmatch(slide, -1) {
+			if _, ok := positions[match[1]]; !ok {
+				positions[match[1]] = i + 1
+			}
+		}
+		folien[i] = tocTargetRegexp.ReplaceAllString(slide, "")
 	}
-	return toc.String()
+
+	for i, slide := range folien {
+		if !IsTOC(slide) {
+			continue
+		}
+		folien[i] = tocNumberRegexp.ReplaceAllStringFunc(slide, func(match string) string {
+			position, ok := positions[tocNumberRegexp.FindStringSubmatch(match)[1]]
+			if !ok {
+				return ""
+			}
+			return fmt.Sprintf(" · %d", position)
+		})
+	}
+	return folien
 }
@@ -0,0 +1,79 @@
+package preprocessor
+
+import (
+	"reflect"
+	"testing"
+)
+
+var tocFolien = []string{
+	"# Intro\n\nWelcome",
+	"## Agenda\n\n```bash\n# not a heading\n```",
+	"# Setup\n\n## Install\n\n### Linux",
+	"## Configure",
+}
+
+func TestCollectTOC(t *testing.T) {
+	expected := []TOCEntry{
+		{Level: 1, Depth: 0, Title: "Intro", Slide: 0, Section: "1"},
+		{Level: 2, Depth: 1, Title: "Agenda", Slide: 1, Section: "1.1"},
+		{Level: 1, Depth: 0, Title: "Setup", Slide: 2, Section: "2"},
+		{Level: 2, Depth: 1, Title: "Install", Slide: 2, Section: "2.1"},
+		{Level: 2, Depth: 1, Title: "Configure", Slide: 3, Section: "2.2"},
+	}
+
+	entries := CollectTOC(tocFolien, 2)
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("CollectTOC() = %+v, want %+v", entries, expected)
+	}
+}
+
+func TestCollectTOCWithoutH1(t *testing.T) {
+	entries := CollectTOC([]string{"## One", "## Two\n\n### Detail"}, 3)
+	var sections []string
+	for _, entry := range entries {
+		sections = append(sections, entry.Section)
+	}
+	if expected := []string{"1", "2", "2.1"}; !reflect.DeepEqual(sections, expected) {
+		t.Errorf("sections = %v, want %v", sections, expected)
+	}
+}
+
+func TestGenerateTOC(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  TOCOptions
+		expected string
+	}{
+		{
+			name:     "H1 only",
+			options:  TOCOptions{},
+			expected: "# Contents\n\n- Intro\n- Setup\n",
+		},
+		{
+			name:     "Nested with slide and section numbers",
+			options:  TOCOptions{Depth: 3, SlideNumbers: true, SectionNumbers: true},
+			expected: "# Contents\n\n- 1 Intro · 2\n  - 1.1 Agenda · 3\n- 2 Setup · 4\n  - 2.1 Install · 4\n    - 2.1.1 Linux · 4\n  - 2.2 Configure · 5\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toc := GenerateTOC(tocFolien, "Contents", "", tt.options)
+			if toc != tt.expected {
+				t.Errorf("GenerateTOC() = %q, want %q", toc, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCollectTOCInheritedHeadings(t *testing.T) {
+	folien := AddHeadings([]string{"# A", "## B", "text", "# C"}, 2)
+
+	var titles []string
+	for _, entry := range CollectTOC(folien, 2) {
+		titles = append(titles, entry.Section+" "+entry.Title)
+	}
+	if expected := []string{"1 A", "1.1 B", "2 C"}; !reflect.DeepEqual(titles, expected) {
+		t.Errorf("entries = %v, want %v", titles, expected)
+	}
+}
@@ -0,0 +1,92 @@
+// Package redact implements masking of secrets in execution output and
+// folien before they are displayed or copied to the clipboard.
+package redact
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// Mask replaces every redacted secret.
+	Mask = "********"
+	// minSecretLength is the minimum length of an environment variable value
+	// to be redacted, shorter values would mask too much unrelated text.
+	minSecretLength = 4
+)
+
+// Config contains the environment variables whose values and the patterns
+// whose matches should be redacted.
+type Config struct {
+	Env      []string `yaml:"env"`
+	Patterns []string `yaml:"patterns"`
+}
+
+// Merge returns a new Config containing the entries of both configs.
+func (c Config) Merge(other Config) Config {
+	return Config{
+		Env:      append(slices.Clone(c.Env), other.Env...),
+		Patterns: append(slices.Clone(c.Patterns), other.Patterns...),
+	}
+}
+
+// Redactor masks secrets in text.
+type Redactor struct {
+	values   []string
+	patterns []*regexp.Regexp
+}
+
+// New creates a Redactor from the given Config, the values of the environment
+// variables are read once.
+func New(c Config) (*Redactor, error) {
+	r := &Redactor{}
+
+	for _, name := range c.Env {
+		value := os.Getenv(name)
+		if len(value) < minSecretLength {
+			continue
+		}
+		r.values = append(r.values, value)
+	}
+	// replace longer values first, so that values containing other values
+	// are masked completely
+	slices.SortFunc(r.values, func(a, b string) int {
+		return len(b) - len(a)
+	})
+
+	for _, pattern := range c.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	return r, nil
+}
+
+// Redact replaces all secrets in the given text with Mask. A nil Redactor
+// returns the text unchanged.
+func (r *Redactor) Redact(text string) string {
+	if r == nil {
+		return text
+	}
+	for _, value := range r.values {
+		text = strings.ReplaceAll(text, value, Mask)
+	}
+	for _, pattern := range r.patterns {
+		text = pattern.ReplaceAllLiteralString(text, Mask)
+	}
+	return text
+}
+
+// RedactAll redacts every element of the given slice in place and returns it.
+func (r *Redactor) RedactAll(texts []string) []string {
+	for i, text := range texts {
+		texts[i] = r.Redact(text)
+	}
+	return texts
+}
@@ -0,0 +1,43 @@
+package redact_test
+
+import (
+	"testing"
+
+	"github.com/c0rydoras/folien/internal/redact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	t.Setenv("FOLIEN_TEST_TOKEN", "s3cr3t-value")
+	t.Setenv("FOLIEN_TEST_SHORT", "abc")
+
+	r, err := redact.New(redact.Config{
+		Env:      []string{"FOLIEN_TEST_TOKEN", "FOLIEN_TEST_SHORT", "FOLIEN_TEST_UNSET"},
+		Patterns: []string{`ghp_[A-Za-z0-9]+`},
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"nothing to see", "nothing to see"},
+		{"token=s3cr3t-value", "token=" + redact.Mask},
+		{"abc is too short to be a secret", "abc is too short to be a secret"},
+		{"Authorization: ghp_abc123XYZ done", "Authorization: " + redact.Mask + " done"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, r.Redact(tt.text))
+	}
+}
+
+func TestRedactNil(t *testing.T) {
+	var r *redact.Redactor
+	assert.Equal(t, "ghp_abc", r.Redact("ghp_abc"))
+}
+
+func TestNewInvalidPattern(t *testing.T) {
+	_, err := redact.New(redact.Config{Patterns: []string{"("}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,307 @@
+// Package remote keeps several folien programs presenting the same file in
+// sync, e.g. the audience view and the presenter view in two terminals. The
+// programs exchange their position over a local Unix socket.
+package remote
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Position is the slide and the reveal step shown by a program.
+type Position struct {
+	Page int `json:"page"`
+	Step int `json:"step"`
+}
+
+// Peer is a program connected to the other programs of the presentation. The
+// first program listens on the socket, the others connect to it and their
+// positions are relayed to each other. When the listening program exits, the
+// others connect again and one of them takes over listening.
+type Peer struct {
+	path      string
+	positions chan Position
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    []net.Conn
+	// last is the last known position, it is exchanged with the programs
+	// connecting later on
+	last   *message
+	closed bool
+}
+
+// message is a position sent over the socket. The time of the navigation
+// orders the positions: a program only follows positions more recent than its
+// own, so that the programs agree on the position after (re)connecting.
+type message struct {
+	Position
+	Time int64 `json:"time"`
+}
+
+const (
+	// reconnectInterval is the time between attempts to connect again after
+	// the connection to the listening program was lost.
+	reconnectInterval = 500 * time.Millisecond
+	// writeTimeout is the time a program may take to receive a position,
+	// programs which are stalled for longer are disconnected.
+	writeTimeout = 200 * time.Millisecond
+)
+
+// SocketPath returns the path of the socket for the presentation of the given
+// file. It is placed in $XDG_RUNTIME_DIR or else in a directory of the current
+// user in the temporary directory, which no other user has access to.
+func SocketPath(fileName string) (string, error) {
+	if abs, err := filepath.Abs(fileName); err == nil {
+		fileName = abs
+	}
+	dir, err := socketDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(fileName))
+	return filepath.Join(dir, fmt.Sprintf("folien-%x.sock", sum[:8])), nil
+}
+
+func socketDir() (string, error) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return dir, nil
+	}
+
+	dir := filepath.Join(os.TempDir(), fmt.Sprintf("folien-%d", os.Getuid()))
+	if err := os.Mkdir(dir, 0o700); err != nil && !os.IsExist(err) {
+		return "", err
+	}
+	// the directory might have been created by another user beforehand
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() || !ownedByUser(info) || info.Mode().Perm()&0o077 != 0 {
+		return "", fmt.Errorf("%s must be a directory only accessible by the current user", dir)
+	}
+	return dir, nil
+}
+
+// ownedByUser reports whether the file is owned by the current user.
+func ownedByUser(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid()
+}
+
+// Connect connects to the programs using the socket at path, it listens on
+// the socket if no other program does.
+func Connect(path string) (*Peer, error) {
+	p := &Peer{path: path, positions: make(chan Position, 16)}
+	if err := p.connect(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// connect dials the socket or listens on it. A lock file next to the socket
+// makes sure that programs starting at the same time agree on which one of
+// them is listening.
+func (p *Peer) connect() error {
+	unlock, err := lock(p.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	conn, err := net.Dial("unix", p.path)
+	if err == nil {
+		p.add(conn, true)
+		return nil
+	}
+
+	// the socket of a program which was not shut down cleanly is removed, as
+	// long as it belongs to the current user
+	if info, statErr := os.Lstat(p.path); statErr == nil {
+		if info.Mode().Type() != os.ModeSocket || !ownedByUser(info) {
+			return fmt.Errorf("%s is not a socket of the current user", p.path)
+		}
+		if err := os.Remove(p.path); err != nil {
+			return err
+		}
+	}
+	listener, err := net.Listen("unix", p.path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(p.path, 0o600); err != nil {
+		_ = listener.Close()
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return listener.Close()
+	}
+	p.listener = listener
+	go p.accept(listener)
+	return nil
+}
+
+// lock locks the file at path until the returned function is called. The lock
+// file is kept, removing it would let two programs lock different files.
+func lock(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	// closing the file releases the lock
+	return func() { _ = f.Close() }, nil
+}
+
+// Positions returns the positions received from the other programs.
+func (p *Peer) Positions() <-chan Position {
+	return p.positions
+}
+
+// Send sends the position to the other programs.
+func (p *Peer) Send(position Position) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.last = &message{Position: position, Time: time.Now().UnixNano()}
+	p.broadcast(*p.last, nil)
+}
+
+// Connected reports whether the program is connected to another program, i.e.
+// whether the position is kept in sync.
+func (p *Peer) Connected() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.conns) > 0
+}
+
+// Close disconnects from the other programs and removes the socket if the
+// peer listens on it.
+func (p *Peer) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	listener, conns := p.listener, p.conns
+	p.conns = nil
+	p.mu.Unlock()
+
+	var errs []error
+	if listener != nil {
+		errs = append(errs, listener.Close())
+	}
+	for _, conn := range conns {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Peer) accept(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		p.add(conn, false)
+	}
+}
+
+// add exchanges the last known position with the program at the other end of
+// the connection and receives the positions it sends. If the connection to the
+// listening program (dialed) is lost, the peer connects again.
+func (p *Peer) add(conn net.Conn, dialed bool) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		_ = conn.Close()
+		return
+	}
+	p.conns = append(p.conns, conn)
+	if p.last != nil {
+		if err := write(conn, *p.last); err != nil {
+			// the reader below notices the closed connection
+			_ = conn.Close()
+		}
+	}
+	p.mu.Unlock()
+
+	go func() {
+		decoder := json.NewDecoder(conn)
+		for {
+			var msg message
+			if err := decoder.Decode(&msg); err != nil {
+				p.remove(conn)
+				if dialed {
+					p.reconnect()
+				}
+				return
+			}
+
+			p.mu.Lock()
+			if p.last != nil && msg.Time <= p.last.Time {
+				p.mu.Unlock()
+				continue
+			}
+			p.last = &msg
+			// the positions are relayed to the other connected programs
+			p.broadcast(msg, conn)
+			p.mu.Unlock()
+			p.positions <- msg.Position
+		}
+	}()
+}
+
+// reconnect connects again until it succeeds or the peer is closed.
+func (p *Peer) reconnect() {
+	for {
+		time.Sleep(reconnectInterval)
+		p.mu.Lock()
+		closed := p.closed
+		p.mu.Unlock()
+		if closed || p.connect() == nil {
+			return
+		}
+	}
+}
+
+// broadcast sends the message to all connections except for the given one,
+// p.mu must be held.
+func (p *Peer) broadcast(msg message, except net.Conn) {
+	for _, conn := range slices.Clone(p.conns) {
+		if conn == except {
+			continue
+		}
+		if err := write(conn, msg); err != nil {
+			_ = conn.Close()
+			p.conns = slices.DeleteFunc(p.conns, func(c net.Conn) bool { return c == conn })
+		}
+	}
+}
+
+// write sends the message over the connection, it fails if the program at the
+// other end does not receive it within writeTimeout.
+func write(conn net.Conn, msg message) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	return json.NewEncoder(conn).Encode(msg)
+}
+
+func (p *Peer) remove(conn net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_ = conn.Close()
+	p.conns = slices.DeleteFunc(p.conns, func(c net.Conn) bool { return c == conn })
+}
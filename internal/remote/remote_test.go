@@ -0,0 +1,273 @@
+package remote_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/c0rydoras/folien/internal/remote"
+)
+
+func receive(t *testing.T, peer *remote.Peer) remote.Position {
+	t.Helper()
+	select {
+	case position := <-peer.Positions():
+		return position
+	case <-time.After(5 * time.Second):
+		t.Fatal("no position received")
+		return remote.Position{}
+	}
+}
+
+func TestConnect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "folien.sock")
+
+	audience, err := remote.Connect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	presenter, err := remote.Connect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// wait for the presenter to be accepted
+	presenter.Send(remote.Position{Page: 1})
+	if got := receive(t, audience); got != (remote.Position{Page: 1}) {
+		t.Errorf("audience received %v", got)
+	}
+
+	audience.Send(remote.Position{Page: 2, Step: 1})
+	if got := receive(t, presenter); got != (remote.Position{Page: 2, Step: 1}) {
+		t.Errorf("presenter received %v", got)
+	}
+
+	// programs connecting later on start at the current position and
+	// positions are relayed between them
+	second, err := remote.Connect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := receive(t, second); got != (remote.Position{Page: 2, Step: 1}) {
+		t.Errorf("second presenter received %v", got)
+	}
+	presenter.Send(remote.Position{Page: 3})
+	if got := receive(t, second); got != (remote.Position{Page: 3}) {
+		t.Errorf("second presenter received %v", got)
+	}
+	if got := receive(t, audience); got != (remote.Position{Page: 3}) {
+		t.Errorf("audience received %v", got)
+	}
+
+	for _, peer := range []*remote.Peer{second, presenter, audience} {
+		if err := peer.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket was not removed: %v", err)
+	}
+}
+
+// waitConnected waits for the peer to be connected to another program.
+func waitConnected(t *testing.T, peer *remote.Peer) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !peer.Connected() {
+		if time.Now().After(deadline) {
+			t.Fatal("peer did not connect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "folien.sock")
+
+	audience, err := remote.Connect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	presenter, err := remote.Connect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer presenter.Close()
+	waitConnected(t, audience)
+	presenter.Send(remote.Position{Page: 4})
+	receive(t, audience)
+
+	// the presenter takes over listening when the audience view exits and the
+	// restarted audience view follows its position
+	if err := audience.Close(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for presenter.Connected() {
+		if time.Now().After(deadline) {
+			t.Fatal("lost connection was not noticed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("presenter did not listen on the socket")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	audience, err = remote.Connect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer audience.Close()
+	if got := receive(t, audience); got != (remote.Position{Page: 4}) {
+		t.Errorf("restarted audience received %v", got)
+	}
+	waitConnected(t, presenter)
+}
+
+func TestConnectConcurrently(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "folien.sock")
+
+	peers := make([]*remote.Peer, 4)
+	var wg sync.WaitGroup
+	for i := range peers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			peer, err := remote.Connect(path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			peers[i] = peer
+		}()
+	}
+	wg.Wait()
+	if t.Failed() {
+		return
+	}
+	for _, peer := range peers {
+		defer peer.Close()
+		waitConnected(t, peer)
+	}
+
+	// all programs share one listener, so every position reaches all of them
+	peers[1].Send(remote.Position{Page: 2})
+	for i, peer := range peers {
+		if i == 1 {
+			continue
+		}
+		if got := receive(t, peer); got != (remote.Position{Page: 2}) {
+			t.Errorf("peer %d received %v", i, got)
+		}
+	}
+}
+
+func TestConnectStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "folien.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+
+	peer, err := remote.Connect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("socket has permissions %o", perm)
+	}
+}
+
+func TestConnectNoSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "folien.sock")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := remote.Connect(path); err == nil {
+		t.Error("expected an error for a file which is no socket")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("file was removed: %v", err)
+	}
+}
+
+func TestSocketPath(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", t.TempDir())
+
+	a, err := remote.SocketPath("a.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := remote.SocketPath("b.md")
+	if a == b {
+		t.Error("files share a socket")
+	}
+	if relative, _ := remote.SocketPath("./a.md"); a != relative {
+		t.Error("relative paths are not resolved")
+	}
+
+	info, err := os.Stat(filepath.Dir(a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o700 {
+		t.Errorf("socket directory has permissions %o", perm)
+	}
+
+	runtime := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtime)
+	if path, _ := remote.SocketPath("a.md"); filepath.Dir(path) != runtime {
+		t.Errorf("socket %s is not in $XDG_RUNTIME_DIR", path)
+	}
+}
+
+func TestSendToStalledProgram(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "folien.sock")
+	peer, err := remote.Connect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer.Close()
+
+	// a program which never reads the positions
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	waitConnected(t, peer)
+
+	done := make(chan struct{})
+	go func() {
+		for i := range 100000 {
+			peer.Send(remote.Position{Page: i})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("sending blocks on the stalled program")
+	}
+	if peer.Connected() {
+		t.Error("stalled program was not disconnected")
+	}
+}
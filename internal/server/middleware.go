@@ -3,6 +3,7 @@ package server
 import (
 	"fmt"
 
+	"github.com/c0rydoras/folien/internal/model"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/ssh"
 	"github.com/charmbracelet/wish"
@@ -10,6 +11,9 @@ import (
 	"github.com/muesli/termenv"
 )
 
+// presentationKey is the key of the presentation of a session in its context.
+type presentationKey struct{}
+
 func slidesMiddleware(srv *Server) wish.Middleware {
 	newProg := func(m tea.Model, opts ...tea.ProgramOption) *tea.Program {
 		p := tea.NewProgram(m, opts...)
@@ -25,7 +29,24 @@ func slidesMiddleware(srv *Server) wish.Middleware {
 			}
 			return nil
 		}
-		return newProg(srv.presentation, tea.WithInput(s), tea.WithOutput(s), tea.WithAltScreen())
+		// every session gets its own state, e.g. for executed code blocks
+		presentation := srv.presentation
+		presentation.ResetState()
+		presentation.Served = true
+		s.Context().SetValue(presentationKey{}, presentation)
+		return newProg(presentation, tea.WithInput(s), tea.WithOutput(s), tea.WithAltScreen())
+	}
+	// the terminals of the session are closed once its program has exited,
+	// the copies of the presentation share them
+	closeTerminals := func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			if presentation, ok := s.Context().Value(presentationKey{}).(model.Model); ok {
+				presentation.CloseTerminals()
+			}
+			next(s)
+		}
+	}
+	return func(next ssh.Handler) ssh.Handler {
+		return bm.MiddlewareWithProgramHandler(teaHandler, termenv.ANSI256)(closeTerminals(next))
 	}
-	return bm.MiddlewareWithProgramHandler(teaHandler, termenv.ANSI256)
 }
@@ -0,0 +1,198 @@
+package terminal
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const tabWidth = 8
+
+// screen is a minimal terminal screen. It understands enough control
+// characters and escape sequences to display shells running with TERM=dumb,
+// all other escape sequences are discarded.
+type screen struct {
+	cols, rows int
+	lines      [][]rune
+	row, col   int
+	// pending contains an incomplete escape sequence or UTF-8 character
+	// from the previous write
+	pending []byte
+}
+
+func newScreen(cols, rows int) *screen {
+	return &screen{
+		cols:  max(cols, 1),
+		rows:  max(rows, 1),
+		lines: [][]rune{{}},
+	}
+}
+
+// Write interprets the output of the program.
+func (s *screen) Write(p []byte) {
+	data := append(s.pending, p...)
+	s.pending = nil
+
+	for len(data) > 0 {
+		switch c := data[0]; {
+		case c == 0x1b:
+			n := escapeLength(data)
+			if n == 0 {
+				s.pending = append([]byte{}, data...)
+				return
+			}
+			s.escape(data[:n])
+			data = data[n:]
+			continue
+		case c == '\r':
+			s.col = 0
+		case c == '\n':
+			s.newline()
+		case c == '\b':
+			s.col = max(0, s.col-1)
+		case c == '\t':
+			s.col = min(s.cols-1, (s.col/tabWidth+1)*tabWidth)
+		case c < 0x20 || c == 0x7f:
+			// ignore other control characters (e.g. bell)
+		default:
+			r, n := utf8.DecodeRune(data)
+			if r == utf8.RuneError && !utf8.FullRune(data) {
+				s.pending = append([]byte{}, data...)
+				return
+			}
+			s.put(r)
+			data = data[n:]
+			continue
+		}
+		data = data[1:]
+	}
+}
+
+func (s *screen) put(r rune) {
+	if s.col >= s.cols {
+		s.newline()
+		s.col = 0
+	}
+	line := s.lines[s.row]
+	for len(line) <= s.col {
+		line = append(line, ' ')
+	}
+	line[s.col] = r
+	s.lines[s.row] = line
+	s.col++
+}
+
+func (s *screen) newline() {
+	s.row++
+	if s.row == len(s.lines) {
+		s.lines = append(s.lines, []rune{})
+	}
+	// only keep the visible lines
+	if len(s.lines) > s.rows {
+		drop := len(s.lines) - s.rows
+		s.lines = s.lines[drop:]
+		s.row -= drop
+	}
+}
+
+// escape handles the few escape sequences needed for line editing and
+// clearing the screen.
+func (s *screen) escape(seq []byte) {
+	if len(seq) < 3 || seq[1] != '[' {
+		return
+	}
+	params := string(seq[2 : len(seq)-1])
+	n := 1
+	if first, _, _ := strings.Cut(params, ";"); first != "" {
+		n = 0
+		for _, c := range first {
+			if c < '0' || c > '9' {
+				break
+			}
+			n = n*10 + int(c-'0')
+		}
+	}
+
+	switch seq[len(seq)-1] {
+	case 'C':
+		s.col = min(s.cols-1, s.col+max(n, 1))
+	case 'D':
+		s.col = max(0, s.col-max(n, 1))
+	case 'K':
+		line := s.lines[s.row]
+		if params == "" || params == "0" {
+			if s.col < len(line) {
+				s.lines[s.row] = line[:s.col]
+			}
+		} else {
+			s.lines[s.row] = []rune{}
+		}
+	case 'J':
+		if params == "2" || params == "3" {
+			s.lines = [][]rune{{}}
+			s.row, s.col = 0, 0
+		}
+	case 'H':
+		if params == "" {
+			s.col = 0
+		}
+	}
+}
+
+// escapeLength returns the length of the escape sequence at the start of
+// data or 0 if it is incomplete.
+func escapeLength(data []byte) int {
+	if len(data) < 2 {
+		return 0
+	}
+	switch data[1] {
+	case '[':
+		// CSI: parameters followed by a final byte in the range @ to ~
+		for i := 2; i < len(data); i++ {
+			if data[i] >= 0x40 && data[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return 0
+	case ']':
+		// OSC: terminated by BEL or ST
+		for i := 2; i < len(data); i++ {
+			if data[i] == 0x07 {
+				return i + 1
+			}
+			if data[i] == 0x1b && i+1 < len(data) && data[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return 0
+	default:
+		return 2
+	}
+}
+
+// View returns the visible lines of the screen, if cursor is set the cursor
+// is drawn at its position.
+func (s *screen) View(cursor bool) string {
+	lines := make([]string, s.rows)
+	for i, line := range s.lines {
+		if cursor && i == s.row {
+			for len(line) <= s.col {
+				line = append(line, ' ')
+			}
+			line = append(append(append([]rune{}, line[:s.col]...), '▌'), line[s.col+1:]...)
+		}
+		lines[i] = strings.TrimRight(string(line), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Resize changes the size of the screen, lines exceeding the new height are
+// dropped from the top.
+func (s *screen) Resize(cols, rows int) {
+	s.cols, s.rows = max(cols, 1), max(rows, 1)
+	if len(s.lines) > s.rows {
+		drop := len(s.lines) - s.rows
+		s.lines = s.lines[drop:]
+		s.row = max(0, s.row-drop)
+	}
+	s.col = min(s.col, s.cols-1)
+}
@@ -0,0 +1,60 @@
+package terminal
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScreen(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   []string
+		expected string
+	}{
+		{"plain text", []string{"$ ls\r\nfile\r\n$ "}, "$ ls\nfile\n$"},
+		{"scrolls", []string{"1\r\n2\r\n3\r\n4\r\n5"}, "3\n4\n5"},
+		{"carriage return overwrites", []string{"hello\rj"}, "jello\n\n"},
+		{"backspace and erase line", []string{"abc\b\b\x1b[K"}, "a\n\n"},
+		{"clear screen", []string{"abc\r\ndef\x1b[H\x1b[2J$ "}, "$\n\n"},
+		{"colors are discarded", []string{"\x1b[1;31mred\x1b[0m"}, "red\n\n"},
+		{"split escape sequence", []string{"a\x1b[3", "1mb"}, "ab\n\n"},
+		{"split utf-8", []string{"\xe2\x96", "\x8c"}, "▌\n\n"},
+		{"wraps long lines", []string{"0123456789ab"}, "0123456789\nab\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScreen(10, 3)
+			for _, o := range tt.output {
+				s.Write([]byte(o))
+			}
+			assert.Equal(t, tt.expected, s.View(false))
+		})
+	}
+}
+
+func TestScreenCursor(t *testing.T) {
+	s := newScreen(10, 2)
+	s.Write([]byte("$ ls\x1b[D"))
+	assert.Equal(t, "$ l▌\n", s.View(true))
+}
+
+func TestKeyBytes(t *testing.T) {
+	tests := []struct {
+		key      tea.KeyMsg
+		expected string
+	}{
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ls")}, "ls"},
+		{tea.KeyMsg{Type: tea.KeyEnter}, "\r"},
+		{tea.KeyMsg{Type: tea.KeyBackspace}, "\x7f"},
+		{tea.KeyMsg{Type: tea.KeyCtrlC}, "\x03"},
+		{tea.KeyMsg{Type: tea.KeyUp}, "\x1b[A"},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b"), Alt: true}, "\x1bb"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, string(KeyBytes(tt.key)), tt.key.String())
+	}
+}
@@ -0,0 +1,181 @@
+// Package terminal implements an interactive shell running in a pseudo
+// terminal, which can be displayed and used inside a slide.
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/creack/pty"
+)
+
+// Language is the language of code blocks which embed a terminal.
+const Language = "terminal"
+
+// Terminal is a shell running in a pseudo terminal.
+type Terminal struct {
+	cmd *exec.Cmd
+	pty *os.File
+
+	mu     sync.Mutex
+	screen *screen
+	exited bool
+
+	// updates receives a value whenever the screen changed, it is closed
+	// when the shell exits
+	updates chan struct{}
+}
+
+// Start starts the shell of the user ($SHELL) inside dir with a screen of the
+// given size.
+func Start(dir string, cols, rows int) (*Terminal, error) {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "sh"
+	}
+
+	cmd := exec.Command(shell)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"TERM=dumb",
+		fmt.Sprintf("COLUMNS=%d", cols),
+		fmt.Sprintf("LINES=%d", rows),
+	)
+
+	f, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Terminal{
+		cmd:     cmd,
+		pty:     f,
+		screen:  newScreen(cols, rows),
+		updates: make(chan struct{}, 1),
+	}
+	go t.read()
+	return t, nil
+}
+
+func (t *Terminal) read() {
+	buf := make([]byte, 4096)
+	for {
+		n, err := t.pty.Read(buf)
+		if n > 0 {
+			t.mu.Lock()
+			t.screen.Write(buf[:n])
+			t.mu.Unlock()
+			select {
+			case t.updates <- struct{}{}:
+			default:
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	_ = t.cmd.Wait()
+	t.mu.Lock()
+	t.exited = true
+	t.mu.Unlock()
+	close(t.updates)
+}
+
+// Wait returns a command which waits until the screen changes. It returns
+// an UpdateMsg with the given id, or an ExitMsg if the shell exited.
+func (t *Terminal) Wait(id int) tea.Cmd {
+	return func() tea.Msg {
+		if _, ok := <-t.updates; !ok {
+			return ExitMsg{ID: id}
+		}
+		return UpdateMsg{ID: id}
+	}
+}
+
+// UpdateMsg is sent when the screen of the terminal with ID changed.
+type UpdateMsg struct {
+	ID int
+}
+
+// ExitMsg is sent when the shell of the terminal with ID exited.
+type ExitMsg struct {
+	ID int
+}
+
+// Write sends input to the shell.
+func (t *Terminal) Write(p []byte) error {
+	_, err := t.pty.Write(p)
+	return err
+}
+
+// Resize changes the size of the pseudo terminal.
+func (t *Terminal) Resize(cols, rows int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if cols == t.screen.cols && rows == t.screen.rows {
+		return
+	}
+	t.screen.Resize(cols, rows)
+	_ = pty.Setsize(t.pty, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+}
+
+// View returns the current screen, with a cursor if the terminal is focused.
+func (t *Terminal) View(focused bool) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.screen.View(focused && !t.exited)
+}
+
+// Exited returns whether the shell has exited.
+func (t *Terminal) Exited() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.exited
+}
+
+// Close terminates the shell.
+func (t *Terminal) Close() error {
+	if t.cmd.Process != nil {
+		_ = t.cmd.Process.Kill()
+	}
+	return t.pty.Close()
+}
+
+// KeyBytes translates a key press into the bytes a terminal would send.
+func KeyBytes(msg tea.KeyMsg) []byte {
+	var b []byte
+	if msg.Alt {
+		b = append(b, 0x1b)
+	}
+
+	switch msg.Type {
+	case tea.KeyRunes:
+		return append(b, string(msg.Runes)...)
+	case tea.KeySpace:
+		return append(b, ' ')
+	case tea.KeyUp:
+		return append(b, "\x1b[A"...)
+	case tea.KeyDown:
+		return append(b, "\x1b[B"...)
+	case tea.KeyRight:
+		return append(b, "\x1b[C"...)
+	case tea.KeyLeft:
+		return append(b, "\x1b[D"...)
+	case tea.KeyHome:
+		return append(b, "\x1b[H"...)
+	case tea.KeyEnd:
+		return append(b, "\x1b[F"...)
+	case tea.KeyDelete:
+		return append(b, "\x1b[3~"...)
+	}
+
+	// control keys are represented by their ASCII codes
+	if (msg.Type >= 0 && msg.Type <= 0x1f) || msg.Type == 0x7f {
+		return append(b, byte(msg.Type))
+	}
+	return nil
+}
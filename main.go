@@ -3,12 +3,15 @@ package main
 import (
 	"context"
 	_ "embed"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/c0rydoras/folien/internal/model"
 	"github.com/c0rydoras/folien/internal/navigation"
 	"github.com/c0rydoras/folien/internal/preprocessor"
+	"github.com/c0rydoras/folien/internal/redact"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/fang"
 	"github.com/spf13/cobra"
@@ -19,21 +22,55 @@ var (
 	tocDescription string
 	enableHeadings bool
 	allowExecution bool
+	redactEnv      []string
+	redactPatterns []string
+	layout         string
+	splitRatio     float64
+	vars           []string
+	tocOptions     preprocessor.TOCOptions
+	headingDepth   int
+	headingMode    string
+	numberHeadings bool
+	pipeline       []string
+	filters        []string
+	renderers      []string
+	incremental    bool
+	profile        string
 )
 
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&enableHeadings, "headings", "a", false, "Enable automatic heading addition")
+	rootCmd.PersistentFlags().IntVar(&headingDepth, "heading-depth", 0, "Deepest heading level inherited by the following folien (default 2)")
+	rootCmd.PersistentFlags().StringVar(&headingMode, "heading-mode", "", "Display inherited headings on the slide (inline) or in the status bar (breadcrumb)")
+	rootCmd.PersistentFlags().BoolVar(&numberHeadings, "number-headings", false, "Number the headings hierarchically (1, 1.1, 1.2, ...)")
+	rootCmd.PersistentFlags().StringSliceVar(&pipeline, "pipeline", nil, "Order of the preprocessor stages (default numbering,headings,footnotes,citations,toc,templates)")
+	rootCmd.PersistentFlags().StringArrayVar(&filters, "filter", nil, "Run this external filter after the preprocessor stages")
+	rootCmd.PersistentFlags().BoolVar(&incremental, "incremental", false, "Reveal the items of lists one at a time")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Include the folien marked for this profile (marked folien are left out by default)")
+	rootCmd.PersistentFlags().StringArrayVar(&renderers, "renderer", nil, "Render code blocks of a language with a command (language=command)")
 	rootCmd.PersistentFlags().BoolVarP(&allowExecution, "allow-execution", "A", false, "Allow executing code blocks")
+	rootCmd.PersistentFlags().StringSliceVar(&redactEnv, "redact-env", nil, "Mask the values of these environment variables in folien and output")
+	rootCmd.PersistentFlags().StringArrayVar(&redactPatterns, "redact", nil, "Mask matches of this regular expression in folien and output")
+
+	rootCmd.PersistentFlags().StringArrayVar(&vars, "var", nil, "Set a template variable (key=value)")
+
+	rootCmd.PersistentFlags().StringVar(&layout, "layout", "", "Where to display the output of code blocks: inline, horizontal (split) or vertical (stacked)")
+	rootCmd.PersistentFlags().Float64Var(&splitRatio, "split-ratio", 0, "Share of the slide in the horizontal and vertical layouts (default 0.5)")
 
 	rootCmd.PersistentFlags().StringVarP(&tocTitle, "toc", "t", "", "Enable table of contents generation with optional title (default: 'Table of Contents')")
 	tocFlag := rootCmd.Flag("toc")
 	tocFlag.NoOptDefVal = "Table of Contents"
 
+	rootCmd.PersistentFlags().IntVar(&tocOptions.Depth, "toc-depth", 1, "Deepest heading level (1-3) listed in the table of contents")
+	rootCmd.PersistentFlags().BoolVar(&tocOptions.SlideNumbers, "toc-slide-numbers", false, "Add slide numbers to the table of contents")
+	rootCmd.PersistentFlags().BoolVar(&tocOptions.SectionNumbers, "toc-section-numbers", false, "Number the sections of the table of contents")
+
 	rootCmd.PersistentFlags().StringVarP(&tocDescription, "toc-description", "d", "", "Enable table of contents generation with optional description")
 	tocDescFlag := rootCmd.Flag("toc-description")
 	tocDescFlag.NoOptDefVal = "Table of Contents Description"
 
 	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(presentCmd)
 }
 
 var rootCmd = &cobra.Command{
@@ -68,17 +105,59 @@ func root(cmd *cobra.Command, args []string) error {
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
-	if _, err := p.Run(); err != nil {
-		return err
+	final, err := p.Run()
+	if presentation, ok := final.(model.Model); ok {
+		presentation.CloseTerminals()
 	}
-	return nil
+	return err
 }
 
 func newModel(fileName string) (model.Model, error) {
-	preprocessorConfig := preprocessor.NewConfig().WithTOC(tocTitle, tocDescription)
+	preprocessorConfig := preprocessor.NewConfig().WithTOC(tocTitle, tocDescription).WithTOCOptions(tocOptions)
 	if enableHeadings {
 		preprocessorConfig = preprocessorConfig.WithHeadings()
 	}
+	switch headingMode {
+	case "", preprocessor.HeadingsInline, preprocessor.HeadingsBreadcrumb:
+	default:
+		return model.Model{}, fmt.Errorf("unknown heading mode %q", headingMode)
+	}
+	preprocessorConfig = preprocessorConfig.WithHeadingDepth(headingDepth).WithHeadingMode(headingMode)
+	if numberHeadings {
+		preprocessorConfig = preprocessorConfig.WithNumbering()
+	}
+	preprocessorConfig = preprocessorConfig.WithPipeline(pipeline, filters).WithBaseDir(preprocessor.BaseDir(fileName))
+	if allowExecution {
+		preprocessorConfig = preprocessorConfig.WithExecution()
+	}
+
+	templateVars := map[string]string{}
+	for _, v := range vars {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return model.Model{}, fmt.Errorf("invalid variable %q, expected key=value", v)
+		}
+		templateVars[key] = value
+	}
+	preprocessorConfig = preprocessorConfig.WithVars(templateVars)
+
+	blockRenderers := map[string]string{}
+	for _, r := range renderers {
+		language, command, ok := strings.Cut(r, "=")
+		if !ok || language == "" {
+			return model.Model{}, fmt.Errorf("invalid renderer %q, expected language=command", r)
+		}
+		blockRenderers[language] = command
+	}
+
+	outputLayout := model.Layout("")
+	if layout != "" {
+		var err error
+		outputLayout, err = model.ParseLayout(layout)
+		if err != nil {
+			return model.Model{}, err
+		}
+	}
 
 	presentation := model.Model{
 		Page:               0,
@@ -88,6 +167,15 @@ func newModel(fileName string) (model.Model, error) {
 		Preprocessor:       preprocessorConfig,
 		HideInternalErrors: model.AllButLast,
 		AllowExecution:     allowExecution,
+		Layout:             outputLayout,
+		SplitRatio:         splitRatio,
+		Renderers:          blockRenderers,
+		Incremental:        incremental,
+		Profile:            profile,
+		Redact: redact.Config{
+			Env:      redactEnv,
+			Patterns: redactPatterns,
+		},
 	}
 	err := presentation.Load()
 	if err != nil {
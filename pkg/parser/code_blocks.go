@@ -1,15 +1,54 @@
 package parser
 
 import (
-	"github.com/yuin/goldmark"
+	"maps"
+	"slices"
+	"strings"
+
 	"github.com/yuin/goldmark/ast"
+	gparser "github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
 )
 
+// fenceOffsetAttribute is the attribute of fenced code blocks containing the
+// offset of their opening fence, see newParser.
+var fenceOffsetAttribute = []byte("fence-offset")
+
+// fenceOffsetParser parses fenced code blocks and records the offset of their
+// opening fence, which is not known otherwise for empty blocks without an
+// info string.
+type fenceOffsetParser struct {
+	gparser.BlockParser
+}
+
+func (p fenceOffsetParser) Open(parent ast.Node, reader text.Reader, pc gparser.Context) (ast.Node, gparser.State) {
+	_, segment := reader.PeekLine()
+	node, state := p.BlockParser.Open(parent, reader, pc)
+	if node != nil {
+		node.SetAttribute(fenceOffsetAttribute, segment.Start)
+	}
+	return node, state
+}
+
+// newParser returns the default markdown parser recording the offsets of the
+// fenced code blocks for BlockRange.
+func newParser() gparser.Parser {
+	blockParsers := gparser.DefaultBlockParsers()
+	for i, p := range blockParsers {
+		if p.Value == gparser.NewFencedCodeBlockParser() {
+			blockParsers[i].Value = fenceOffsetParser{gparser.NewFencedCodeBlockParser()}
+		}
+	}
+	return gparser.NewParser(
+		gparser.WithBlockParsers(blockParsers...),
+		gparser.WithInlineParsers(gparser.DefaultInlineParsers()...),
+		gparser.WithParagraphTransformers(gparser.DefaultParagraphTransformers()...),
+	)
+}
+
 func CollectCodeBlocks(source []byte) []*ast.FencedCodeBlock {
-	parser := goldmark.DefaultParser()
 	reader := text.NewReader(source)
-	doc := parser.Parse(reader)
+	doc := newParser().Parse(reader)
 
 	codeBlocks := []*ast.FencedCodeBlock{}
 
@@ -29,3 +68,154 @@ func CollectCodeBlocks(source []byte) []*ast.FencedCodeBlock {
 	}
 	return codeBlocks
 }
+
+// IndentedCodeRanges returns the byte ranges of the indented code blocks in
+// source.
+func IndentedCodeRanges(source []byte) [][2]int {
+	doc := newParser().Parse(text.NewReader(source))
+
+	var ranges [][2]int
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if block, ok := n.(*ast.CodeBlock); ok && entering && block.Lines().Len() > 0 {
+			lines := block.Lines()
+			ranges = append(ranges, [2]int{lineStart(source, lines.At(0).Start), lines.At(lines.Len() - 1).Stop})
+		}
+		return ast.WalkContinue, nil
+	})
+	return ranges
+}
+
+// ParseInfo splits the info string of a fenced code block into its language
+// and its attributes. Attributes are written as key=value pairs after the
+// language, values may be quoted to contain spaces. Attributes without a value
+// are set to "true".
+//
+//	go name=generate stdin=@other title="Hello World"
+func ParseInfo(info string) (string, map[string]string) {
+	fields := splitInfo(info)
+	attributes := map[string]string{}
+	if len(fields) == 0 {
+		return "", attributes
+	}
+
+	language := fields[0]
+	if strings.Contains(language, "=") {
+		language = ""
+	} else {
+		fields = fields[1:]
+	}
+
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			value = "true"
+		}
+		attributes[key] = strings.Trim(value, `"`)
+	}
+	return language, attributes
+}
+
+// FormatInfo is the inverse of ParseInfo, it returns the info string for the
+// given language and attributes. Attributes are sorted by their key.
+func FormatInfo(language string, attributes map[string]string) string {
+	fields := []string{}
+	if language != "" {
+		fields = append(fields, language)
+	}
+	for _, key := range slices.Sorted(maps.Keys(attributes)) {
+		value := attributes[key]
+		switch {
+		case value == "true" && len(fields) > 0:
+			// a bare key in the first field would be read as the language
+			fields = append(fields, key)
+		case value == "" || strings.Contains(value, " "):
+			fields = append(fields, key+`="`+value+`"`)
+		default:
+			fields = append(fields, key+"="+value)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// Attributes returns the attributes of the info string of a fenced code
+// block. See ParseInfo for the syntax.
+func Attributes(block *ast.FencedCodeBlock, source []byte) map[string]string {
+	if block.Info == nil {
+		return map[string]string{}
+	}
+	_, attributes := ParseInfo(string(block.Info.Segment.Value(source)))
+	return attributes
+}
+
+// BlockRange returns the byte range of the whole fenced code block in source,
+// including the opening and closing fence lines.
+func BlockRange(block *ast.FencedCodeBlock, source []byte) (int, int) {
+	start := 0
+	offset, ok := block.AttributeString(string(fenceOffsetAttribute))
+	switch {
+	case ok:
+		start = offset.(int)
+	case block.Info != nil:
+		start = block.Info.Segment.Start
+	case block.Lines().Len() > 0:
+		// the opening fence is the line before the first line of code
+		start = max(0, block.Lines().At(0).Start-1)
+	}
+	start = lineStart(source, start)
+
+	// the closing fence is the line after the last line of code
+	stop := lineEnd(source, start)
+	if block.Lines().Len() > 0 {
+		stop = block.Lines().At(block.Lines().Len() - 1).Stop
+	}
+	if stop < len(source) {
+		closing := strings.TrimLeft(string(source[stop:lineEnd(source, stop)]), " ")
+		if strings.HasPrefix(closing, "```") || strings.HasPrefix(closing, "~~~") {
+			stop = lineEnd(source, stop)
+		}
+	}
+	return start, stop
+}
+
+// lineStart returns the offset of the start of the line containing offset.
+func lineStart(source []byte, offset int) int {
+	for offset > 0 && source[offset-1] != '\n' {
+		offset--
+	}
+	return offset
+}
+
+// lineEnd returns the offset after the newline ending the line containing
+// offset.
+func lineEnd(source []byte, offset int) int {
+	for offset < len(source) && source[offset] != '\n' {
+		offset++
+	}
+	return min(offset+1, len(source))
+}
+
+func splitInfo(info string) []string {
+	var (
+		fields  []string
+		current strings.Builder
+		quoted  bool
+	)
+	for _, r := range strings.TrimSpace(info) {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case r == ' ' && !quoted:
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
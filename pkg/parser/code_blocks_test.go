@@ -0,0 +1,73 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/c0rydoras/folien/pkg/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInfo(t *testing.T) {
+	tests := []struct {
+		info       string
+		language   string
+		attributes map[string]string
+	}{
+		{"", "", map[string]string{}},
+		{"go", "go", map[string]string{}},
+		{"bash name=generate stdin=@other", "bash", map[string]string{"name": "generate", "stdin": "@other"}},
+		{`python title="Hello World" template`, "python", map[string]string{"title": "Hello World", "template": "true"}},
+		{"file=main.go lines=1-3", "", map[string]string{"file": "main.go", "lines": "1-3"}},
+	}
+
+	for _, tt := range tests {
+		language, attributes := parser.ParseInfo(tt.info)
+		assert.Equal(t, tt.language, language, tt.info)
+		assert.Equal(t, tt.attributes, attributes, tt.info)
+	}
+}
+
+func TestFormatInfo(t *testing.T) {
+	tests := []struct {
+		language   string
+		attributes map[string]string
+		info       string
+	}{
+		{"", map[string]string{}, ""},
+		{"go", map[string]string{"name": "main", "bench": "true"}, "go bench name=main"},
+		{"python", map[string]string{"title": "Hello World"}, `python title="Hello World"`},
+		{"", map[string]string{"hidden": "true"}, "hidden=true"},
+	}
+
+	for _, tt := range tests {
+		info := parser.FormatInfo(tt.language, tt.attributes)
+		assert.Equal(t, tt.info, info)
+		language, attributes := parser.ParseInfo(info)
+		assert.Equal(t, tt.language, language, info)
+		assert.Equal(t, tt.attributes, attributes, info)
+	}
+}
+
+func TestBlockRange(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"text\n\n```go name=x\nfmt.Println()\n```\n\nmore", "```go name=x\nfmt.Println()\n```\n"},
+		{"text\n\n~~~\nplain\n~~~", "~~~\nplain\n~~~"},
+		{"  ```demo\n  ls\n  ```\nafter", "  ```demo\n  ls\n  ```\n"},
+		{"```bash\n```\n", "```bash\n```\n"},
+		{"```bash\nunclosed\n", "```bash\nunclosed\n"},
+		{"# Title\n\n```\n```\nafter", "```\n```\n"},
+		{"# Title\n\n- item\n\n  ~~~\n  ~~~\n", "  ~~~\n  ~~~\n"},
+		{"# Title\n\n```\n", "```\n"},
+	}
+
+	for _, tt := range tests {
+		source := []byte(tt.source)
+		blocks := parser.CollectCodeBlocks(source)
+		assert.Len(t, blocks, 1, tt.source)
+		start, stop := parser.BlockRange(blocks[0], source)
+		assert.Equal(t, tt.expected, string(source[start:stop]), tt.source)
+	}
+}
@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"strings"
+)
+
+// FenceTracker tracks whether the lines of a markdown document, fed to it one
+// after another, are part of a fenced code block. This is useful for line
+// based processing which should leave code blocks untouched.
+type FenceTracker struct {
+	char   byte
+	length int
+}
+
+// Inside returns whether the given line is part of a fenced code block,
+// including its opening and closing fence.
+func (f *FenceTracker) Inside(line string) bool {
+	trimmed := strings.TrimRight(line, "\r\n")
+	indented := strings.TrimLeft(trimmed, " ")
+	if len(trimmed)-len(indented) > 3 {
+		return f.length > 0
+	}
+
+	if f.length == 0 {
+		char, length := fence(indented)
+		if length >= 3 {
+			// backtick fences must not contain backticks in the info string
+			if char == '`' && strings.Contains(indented[length:], "`") {
+				return false
+			}
+			f.char, f.length = char, length
+			return true
+		}
+		return false
+	}
+
+	char, length := fence(indented)
+	if char == f.char && length >= f.length && strings.TrimSpace(indented[length:]) == "" {
+		f.length = 0
+	}
+	return true
+}
+
+// fence returns the fence character and the length of the fence the line
+// starts with.
+func fence(line string) (byte, int) {
+	if line == "" || (line[0] != '`' && line[0] != '~') {
+		return 0, 0
+	}
+	length := 0
+	for length < len(line) && line[length] == line[0] {
+		length++
+	}
+	return line[0], length
+}
@@ -0,0 +1,19 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/c0rydoras/folien/pkg/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFenceTracker(t *testing.T) {
+	source := "text\n```go\ncode\n~~~\n```\nafter\n~~~~ yaml\n---\n~~~\n~~~~\n    ```\nend"
+	expected := []bool{false, true, true, true, true, false, true, true, true, true, false, false}
+
+	var tracker parser.FenceTracker
+	for i, line := range strings.Split(source, "\n") {
+		assert.Equal(t, expected[i], tracker.Inside(line), "line %d: %q", i, line)
+	}
+}
@@ -32,6 +32,14 @@ func UnmarshalFrontMatter[T any](source []byte) (T, error) {
 	return data, nil
 }
 
+// HasFrontMatter returns whether the source starts with a frontmatter, i.e.
+// a YAML or TOML mapping between --- or +++ lines, rather than with a slide
+// separator.
+func HasFrontMatter(source string) bool {
+	data, err := UnmarshalFrontMatter[map[string]any]([]byte(source))
+	return err == nil && len(data) > 0
+}
+
 var frontMatterRegex = regexp.MustCompile(`(?s)^([-+]{3})\n(.*?\n)([-+]{3})\n`)
 
 func RemoveFrontMatter(source string) string {
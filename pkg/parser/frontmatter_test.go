@@ -99,3 +99,10 @@ This document has no frontmatter, just regular markdown content.`
 	assert.Contains(t, result, "# Just Content")
 	assert.Contains(t, result, "This document has no frontmatter")
 }
+
+func TestHasFrontMatter(t *testing.T) {
+	assert.True(t, parser.HasFrontMatter("---\ntitle: Test\n---\n# Content"))
+	assert.True(t, parser.HasFrontMatter("+++\ntitle = \"Test\"\n+++\n# Content"))
+	assert.False(t, parser.HasFrontMatter("---\n# First slide\n---\n# Second slide"))
+	assert.False(t, parser.HasFrontMatter("# Content"))
+}
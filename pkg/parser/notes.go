@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// notesRegexp matches speaker notes (<!-- notes: ... -->), which may span
+// several lines.
+var notesRegexp = regexp.MustCompile(`(?s)<!--\s*notes:(.*?)-->`)
+
+// Notes returns the speaker notes of the slide and the slide without them.
+// Notes in code blocks are part of the code and kept, the notes of several
+// comments are separated by blank lines.
+func Notes(slide string) (string, string) {
+	if !strings.Contains(slide, "notes:") {
+		return "", slide
+	}
+
+	source := []byte(slide)
+	var fences [][2]int
+	for _, block := range CollectCodeBlocks(source) {
+		start, stop := BlockRange(block, source)
+		fences = append(fences, [2]int{start, stop})
+	}
+
+	var (
+		notes []string
+		b     strings.Builder
+		last  int
+	)
+	for _, indices := range notesRegexp.FindAllStringSubmatchIndex(slide, -1) {
+		start, stop := indices[0], indices[1]
+		if inRanges(fences, start) {
+			continue
+		}
+		if text := strings.TrimSpace(slide[indices[2]:indices[3]]); text != "" {
+			notes = append(notes, text)
+		}
+		// comments on lines of their own are removed with the line
+		if lineStart(source, start) == start && strings.HasPrefix(slide[stop:], "\n") {
+			stop++
+		}
+		b.WriteString(slide[last:start])
+		last = stop
+	}
+	b.WriteString(slide[last:])
+	return strings.Join(notes, "\n\n"), b.String()
+}
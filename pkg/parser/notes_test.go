@@ -0,0 +1,55 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/c0rydoras/folien/pkg/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotes(t *testing.T) {
+	tests := []struct {
+		name          string
+		slide         string
+		expectedNotes string
+		expectedSlide string
+	}{
+		{
+			name:          "No notes",
+			slide:         "# Title\n\n<!-- pause -->\nText\n",
+			expectedSlide: "# Title\n\n<!-- pause -->\nText\n",
+		},
+		{
+			name:          "Single line",
+			slide:         "# Title\n<!-- notes: Greet the audience -->\nText\n",
+			expectedNotes: "Greet the audience",
+			expectedSlide: "# Title\nText\n",
+		},
+		{
+			name:          "Several lines",
+			slide:         "# Title\n\n<!--\nnotes:\n- first\n- second\n-->\n",
+			expectedNotes: "- first\n- second",
+			expectedSlide: "# Title\n\n",
+		},
+		{
+			name:          "Several comments",
+			slide:         "Text <!-- notes: one --> more\n<!-- notes: two -->\n",
+			expectedNotes: "one\n\ntwo",
+			expectedSlide: "Text  more\n",
+		},
+		{
+			name:          "Code block",
+			slide:         "```html\n<!-- notes: code -->\n```\n<!-- notes: slide -->\n",
+			expectedNotes: "slide",
+			expectedSlide: "```html\n<!-- notes: code -->\n```\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notes, slide := parser.Notes(tt.slide)
+			assert.Equal(t, tt.expectedNotes, notes)
+			assert.Equal(t, tt.expectedSlide, slide)
+		})
+	}
+}
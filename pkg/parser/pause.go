@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// pauseRegexp matches the lines of pause markers (<!-- pause -->).
+var pauseRegexp = regexp.MustCompile(`(?m)^[ \t]*<!--\s*pause\s*-->[ \t]*$`)
+
+// Pauses returns the offsets at which the slide is cut to reveal it step by
+// step: the pause markers outside of code blocks and, if lists is set, the
+// items of top-level lists except for the first one. Pauses without content
+// before or after them are left out.
+func Pauses(slide string, lists bool) []int {
+	var offsets []int
+	var fences FenceTracker
+	offset := 0
+	for _, line := range strings.SplitAfter(slide, "\n") {
+		if !fences.Inside(line) && pauseRegexp.MatchString(line) {
+			offsets = append(offsets, offset)
+		}
+		offset += len(line)
+	}
+	if lists {
+		offsets = append(offsets, listItems([]byte(slide))...)
+	}
+
+	slices.Sort(offsets)
+	offsets = slices.Compact(offsets)
+	return slices.DeleteFunc(offsets, func(offset int) bool {
+		rest := pauseRegexp.ReplaceAllString(slide[offset:], "")
+		return strings.TrimSpace(slide[:offset]) == "" || strings.TrimSpace(rest) == ""
+	})
+}
+
+// Reveal returns the part of the slide shown at the given step, counted from
+// 0. The whole slide is shown at the last step.
+func Reveal(slide string, step int, lists bool) string {
+	pauses := Pauses(slide, lists)
+	if step < 0 || step >= len(pauses) {
+		return slide
+	}
+	return slide[:pauses[step]]
+}
+
+// listItems returns the offsets of the lines starting the items of top-level
+// lists, except for the first item of each list.
+func listItems(source []byte) []int {
+	doc := newParser().Parse(text.NewReader(source))
+
+	var offsets []int
+	for list := doc.FirstChild(); list != nil; list = list.NextSibling() {
+		if _, ok := list.(*ast.List); !ok {
+			continue
+		}
+		for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+			if item == list.FirstChild() {
+				continue
+			}
+			if start, ok := firstLine(item); ok {
+				offsets = append(offsets, lineStart(source, start))
+			}
+		}
+	}
+	return offsets
+}
+
+// firstLine returns the offset of the first line of content of the node.
+func firstLine(node ast.Node) (int, bool) {
+	if node.Type() == ast.TypeBlock && node.Lines().Len() > 0 {
+		return node.Lines().At(0).Start, true
+	}
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if start, ok := firstLine(child); ok {
+			return start, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,52 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/c0rydoras/folien/pkg/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReveal(t *testing.T) {
+	slide := "# Title\n\nFirst\n\n<!-- pause -->\n\n```md\n<!-- pause -->\n```\n\n<!--pause-->\n- a\n- b\n  - nested\n- c\n"
+
+	tests := []struct {
+		name     string
+		lists    bool
+		expected []string
+	}{
+		{
+			name: "Pause markers",
+			expected: []string{
+				"# Title\n\nFirst\n\n",
+				"# Title\n\nFirst\n\n<!-- pause -->\n\n```md\n<!-- pause -->\n```\n\n",
+				slide,
+			},
+		},
+		{
+			name:  "List items",
+			lists: true,
+			expected: []string{
+				"# Title\n\nFirst\n\n",
+				"# Title\n\nFirst\n\n<!-- pause -->\n\n```md\n<!-- pause -->\n```\n\n",
+				"# Title\n\nFirst\n\n<!-- pause -->\n\n```md\n<!-- pause -->\n```\n\n<!--pause-->\n- a\n",
+				"# Title\n\nFirst\n\n<!-- pause -->\n\n```md\n<!-- pause -->\n```\n\n<!--pause-->\n- a\n- b\n  - nested\n",
+				slide,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, parser.Pauses(slide, tt.lists), len(tt.expected)-1)
+			for step, expected := range tt.expected {
+				assert.Equal(t, expected, parser.Reveal(slide, step, tt.lists), "step %d", step)
+			}
+		})
+	}
+}
+
+func TestPausesWithoutContent(t *testing.T) {
+	assert.Empty(t, parser.Pauses("<!-- pause -->\n# Title\n<!-- pause -->\n\n<!-- pause -->\n", false))
+	assert.Empty(t, parser.Pauses("- only one item\n", true))
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// markerRegexp matches the lines of the markers selecting folien:
+	// <!-- skip -->, <!-- profile: long, workshop --> and <!-- appendix -->.
+	markerRegexp = regexp.MustCompile(`^[ \t]*<!--\s*(skip|appendix|profile:([^>]*?))\s*-->[ \t]*$`)
+)
+
+// Select returns the folien included in the profile. Folien marked with
+// <!-- skip --> are left out, folien marked with <!-- profile: long --> are
+// only included in the listed profiles (separated by commas), so they are left
+// out if profile is empty. The markers are removed, the appendix marker is
+// kept.
+func Select(folien []string, profile string) []string {
+	var selected []string
+	for _, slide := range folien {
+		include := true
+		slide = removeMarkers(slide, func(marker, profiles string) bool {
+			switch marker {
+			case "skip":
+				include = false
+			case "appendix":
+				return false
+			default:
+				include = include && slices.ContainsFunc(strings.Split(profiles, ","), func(p string) bool {
+					return strings.TrimSpace(p) == profile
+				})
+			}
+			return true
+		})
+		if include {
+			selected = append(selected, slide)
+		}
+	}
+	return selected
+}
+
+// Appendix returns the index of the first slide of the appendix, marked with
+// <!-- appendix -->, and the folien without the marker. The index is the
+// number of folien if there is no appendix.
+func Appendix(folien []string) (int, []string) {
+	appendix := len(folien)
+	result := make([]string, len(folien))
+	for i, slide := range folien {
+		result[i] = removeMarkers(slide, func(marker, _ string) bool {
+			if marker != "appendix" {
+				return false
+			}
+			appendix = min(appendix, i)
+			return true
+		})
+	}
+	return appendix, result
+}
+
+// removeMarkers removes the lines of the markers outside of code blocks for
+// which remove returns true.
+func removeMarkers(slide string, remove func(marker, profiles string) bool) string {
+	if !strings.Contains(slide, "<!--") {
+		return slide
+	}
+
+	var (
+		b      strings.Builder
+		fences FenceTracker
+	)
+	for _, line := range strings.SplitAfter(slide, "\n") {
+		if fences.Inside(line) {
+			b.WriteString(line)
+			continue
+		}
+		matches := markerRegexp.FindStringSubmatch(strings.TrimSuffix(line, "\n"))
+		if matches != nil {
+			marker, _, _ := strings.Cut(matches[1], ":")
+			if remove(marker, matches[2]) {
+				continue
+			}
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/c0rydoras/folien/pkg/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelect(t *testing.T) {
+	folien := []string{
+		"# Intro\n",
+		"# Draft\n<!-- skip -->\n",
+		"# Deep dive\n<!--profile: long, workshop-->\n",
+		"# Example\n```html\n<!-- skip -->\n```\n",
+		"<!-- appendix -->\n# Backup\n",
+	}
+
+	tests := []struct {
+		name     string
+		profile  string
+		expected []string
+	}{
+		{
+			name: "No profile",
+			expected: []string{
+				"# Intro\n",
+				"# Example\n```html\n<!-- skip -->\n```\n",
+				"<!-- appendix -->\n# Backup\n",
+			},
+		},
+		{
+			name:    "Profile",
+			profile: "workshop",
+			expected: []string{
+				"# Intro\n",
+				"# Deep dive\n",
+				"# Example\n```html\n<!-- skip -->\n```\n",
+				"<!-- appendix -->\n# Backup\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parser.Select(folien, tt.profile))
+		})
+	}
+}
+
+func TestAppendix(t *testing.T) {
+	appendix, folien := parser.Appendix([]string{"# Intro\n", "<!-- appendix -->\n# Backup\n", "# More\n<!-- appendix -->\n"})
+	assert.Equal(t, 1, appendix)
+	assert.Equal(t, []string{"# Intro\n", "# Backup\n", "# More\n"}, folien)
+
+	appendix, folien = parser.Appendix([]string{"# Intro\n"})
+	assert.Equal(t, 1, appendix)
+	assert.Equal(t, []string{"# Intro\n"}, folien)
+}
@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// DefaultSeparator is the line separating the folien unless another separator
+// is configured.
+const DefaultSeparator = "---"
+
+var headingSeparatorRegexp = regexp.MustCompile(`^h([1-6])$`)
+
+// Split splits the content into folien at the separator, which is either
+//
+//   - a line separating the folien, e.g. *** or <!-- slide -->,
+//   - a regular expression between slashes matching the separating lines,
+//     e.g. /^-{3,}$/, or
+//   - a heading level (h1 to h6), each top-level heading of this level or
+//     above starts a new slide.
+//
+// The separating lines are removed, headings are kept. Lines of code blocks,
+// HTML blocks and a leading frontmatter never separate folien.
+func Split(content, separator string) ([]string, error) {
+	if separator == "" {
+		separator = DefaultSeparator
+	}
+	source := []byte(content)
+	doc := newParser().Parse(text.NewReader(source))
+	protected := protectedRanges(doc, source)
+
+	var split func(offset int, line string) (split bool, keep bool)
+	if matches := headingSeparatorRegexp.FindStringSubmatch(separator); matches != nil {
+		headings := headingLines(doc, source, int(matches[1][0]-'0'))
+		split = func(offset int, _ string) (bool, bool) {
+			return headings[offset], true
+		}
+	} else if len(separator) > 2 && strings.HasPrefix(separator, "/") && strings.HasSuffix(separator, "/") {
+		pattern, err := regexp.Compile(separator[1 : len(separator)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid separator %s: %w", separator, err)
+		}
+		split = func(_ int, line string) (bool, bool) {
+			return pattern.MatchString(line), false
+		}
+	} else {
+		split = func(_ int, line string) (bool, bool) {
+			return strings.TrimRight(line, " \t") == separator, false
+		}
+	}
+
+	return splitLines(content, func(offset int, line string) (bool, bool) {
+		if inRanges(protected, offset) {
+			return false, false
+		}
+		return split(offset, line)
+	}), nil
+}
+
+// splitLines splits the content before the lines for which split returns
+// true, the line is kept if keep is true. Empty folien before the first split
+// are left out.
+func splitLines(content string, split func(offset int, line string) (split bool, keep bool)) []string {
+	var (
+		folien  []string
+		current strings.Builder
+		offset  int
+	)
+	for _, line := range strings.SplitAfter(content, "\n") {
+		start := offset
+		offset += len(line)
+		ok, keep := split(start, strings.TrimSuffix(line, "\n"))
+		if !ok {
+			current.WriteString(line)
+			continue
+		}
+		if slide := current.String(); len(folien) > 0 || strings.TrimSpace(slide) != "" {
+			folien = append(folien, strings.TrimSuffix(slide, "\n"))
+		}
+		current.Reset()
+		if keep {
+			current.WriteString(line)
+		}
+	}
+	return append(folien, current.String())
+}
+
+// headingLines returns the offsets of the lines starting the top-level
+// headings of the given level or above.
+func headingLines(doc ast.Node, source []byte, level int) map[int]bool {
+	lines := map[int]bool{}
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		heading, ok := node.(*ast.Heading)
+		if !ok || heading.Level > level || heading.Lines().Len() == 0 {
+			continue
+		}
+		lines[lineStart(source, heading.Lines().At(0).Start)] = true
+	}
+	return lines
+}
+
+// protectedRanges returns the ranges of the code blocks, the HTML blocks
+// except for their first line and the frontmatter.
+func protectedRanges(doc ast.Node, source []byte) [][2]int {
+	var ranges [][2]int
+	if match := frontMatterRegex.FindSubmatchIndex(source); match != nil && string(source[match[2]:match[3]]) == string(source[match[6]:match[7]]) {
+		ranges = append(ranges, [2]int{0, match[1]})
+	}
+
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := node.(type) {
+		case *ast.FencedCodeBlock:
+			start, stop := BlockRange(node, source)
+			ranges = append(ranges, [2]int{start, stop})
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeBlock, *ast.HTMLBlock:
+			lines := node.Lines()
+			if lines.Len() == 0 {
+				return ast.WalkSkipChildren, nil
+			}
+			start, stop := lineStart(source, lines.At(0).Start), lines.At(lines.Len()-1).Stop
+			if html, ok := node.(*ast.HTMLBlock); ok {
+				// the first line of HTML blocks may be the separator itself,
+				// e.g. <!-- slide -->
+				start = lineEnd(source, start)
+				if html.HasClosure() {
+					stop = html.ClosureLine.Stop
+				}
+			}
+			ranges = append(ranges, [2]int{start, stop})
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return ranges
+}
+
+func inRanges(ranges [][2]int, offset int) bool {
+	for _, r := range ranges {
+		if offset >= r[0] && offset < r[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/c0rydoras/folien/pkg/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		separator string
+		expected  []string
+	}{
+		{
+			name:     "Default",
+			content:  "# One\n---\n# Two\n",
+			expected: []string{"# One", "# Two\n"},
+		},
+		{
+			name:     "Text before the separator",
+			content:  "Text\n---\nMore text\n",
+			expected: []string{"Text", "More text\n"},
+		},
+		{
+			name:     "Code blocks",
+			content:  "# YAML\n```yaml\na: 1\n---\nb: 2\n```\n---\n~~~diff\n---\n+++\n~~~\n",
+			expected: []string{"# YAML\n```yaml\na: 1\n---\nb: 2\n```", "~~~diff\n---\n+++\n~~~\n"},
+		},
+		{
+			name:     "HTML blocks",
+			content:  "<!--\n---\n-->\n---\n<div>\n---\n</div>\n",
+			expected: []string{"<!--\n---\n-->", "<div>\n---\n</div>\n"},
+		},
+		{
+			name:     "Frontmatter",
+			content:  "---\nunknown: true\n---\n# One\n---\n# Two",
+			expected: []string{"---\nunknown: true\n---\n# One", "# Two"},
+		},
+		{
+			name:      "Literal",
+			content:   "# One\n<!-- slide -->\n# Two\n---\nText\n",
+			separator: "<!-- slide -->",
+			expected:  []string{"# One", "# Two\n---\nText\n"},
+		},
+		{
+			name:      "Regular expression",
+			content:   "# One\n***\n# Two\n* * *\n# Three\n```\n***\n```\n",
+			separator: `/^(\* ?){3}$/`,
+			expected:  []string{"# One", "# Two", "# Three\n```\n***\n```\n"},
+		},
+		{
+			name:      "Second level headings",
+			content:   "# Title\n\nIntro\n\n## One\n\nText\n\n### Details\n\n```sh\n## comment\n```\n## Two\n",
+			separator: "h2",
+			expected: []string{
+				"# Title\n\nIntro\n",
+				"## One\n\nText\n\n### Details\n\n```sh\n## comment\n```",
+				"## Two\n",
+			},
+		},
+		{
+			name:      "First level headings",
+			content:   "\n# One\n## Sub\n#Not a heading\n> # Quoted\n\nTwo\n===\n",
+			separator: "h1",
+			expected:  []string{"# One\n## Sub\n#Not a heading\n> # Quoted\n", "Two\n===\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folien, err := parser.Split(tt.content, tt.separator)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, folien)
+		})
+	}
+}
+
+func TestSplitInvalidRegexp(t *testing.T) {
+	_, err := parser.Split("# One", "/(/")
+	assert.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"github.com/c0rydoras/folien/internal/model"
+	"github.com/c0rydoras/folien/internal/remote"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var (
+	presenter  bool
+	socketPath string
+)
+
+// presentCmd is the command for presenting on two displays. The audience view
+// and the presenter view are started in two terminals and kept in sync over a
+// Unix socket.
+var presentCmd = &cobra.Command{
+	Use:   "present <file.md>",
+	Short: "Present with the audience view and the presenter view kept in sync",
+	Args:  cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return cmd.Help()
+		}
+
+		presentation, err := newModel(args[0])
+		if err != nil {
+			return err
+		}
+		presentation.Presenter = presenter
+
+		if socketPath == "" {
+			socketPath, err = remote.SocketPath(args[0])
+			if err != nil {
+				return err
+			}
+		}
+		presentation.Remote, err = remote.Connect(socketPath)
+		if err != nil {
+			return err
+		}
+		defer presentation.Remote.Close()
+
+		p := tea.NewProgram(
+			presentation,
+			tea.WithAltScreen(),
+			tea.WithMouseCellMotion(),
+		)
+		final, err := p.Run()
+		if presentation, ok := final.(model.Model); ok {
+			presentation.CloseTerminals()
+		}
+		return err
+	},
+}
+
+func init() {
+	presentCmd.Flags().BoolVar(&presenter, "presenter", false, "Show the notes, a preview of the next slide and a timer")
+	presentCmd.Flags().StringVar(&socketPath, "socket", "", "Unix socket used to sync the views (default derived from the file)")
+}
@@ -34,9 +34,29 @@ var (
 	// Status is the style for the status bar at the bottom of the
 	// presentation.
 	Status = lipgloss.NewStyle().Padding(1)
+	// Breadcrumb is the style for the inherited headings in the status bar.
+	Breadcrumb = lipgloss.NewStyle().Foreground(salmon).Faint(true).Align(lipgloss.Left).Margin(0, 1)
 	// Search is the style for the search input at the bottom-left corner of
 	// the screen when searching is active.
 	Search = lipgloss.NewStyle().Faint(true).Align(lipgloss.Left).MarginLeft(2)
+	// Hidden is the style for hidden lines of code blocks when they are
+	// revealed.
+	Hidden = lipgloss.NewStyle().Faint(true)
+	// Output is the style for the pane displaying the output of executed code
+	// blocks in split layouts.
+	Output = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240")).Padding(0, 1)
+	// OutputFocused is the style for the output pane when it receives the
+	// scroll keys.
+	OutputFocused = Output.BorderForeground(salmon)
+	// Selected is the style for the entry under the cursor in the table of
+	// contents overlay.
+	Selected = lipgloss.NewStyle().Foreground(salmon).Bold(true)
+	// Label is the style for the labels of the sections of the presenter
+	// view.
+	Label = lipgloss.NewStyle().Foreground(salmon).Bold(true).MarginLeft(2)
+	// Unsynced is the style for the notice in the status bar when the view is
+	// not kept in sync with another one.
+	Unsynced = lipgloss.NewStyle().Faint(true).Italic(true).MarginRight(1)
 )
 
 var (
@@ -57,6 +77,12 @@ func JoinVertical(top, bottom string, height int) string {
 	return lipgloss.PlaceVertical(h, lipgloss.Top, top) + bottom
 }
 
+// JoinPanes joins two multi-line blocks horizontally, the left block is padded
+// to the given width.
+func JoinPanes(left, right string, width int) string {
+	return lipgloss.JoinHorizontal(lipgloss.Top, lipgloss.PlaceHorizontal(width, lipgloss.Left, left), right)
+}
+
 // SelectTheme picks a glamour style config based
 // on the theme provided in the markdown header
 func SelectTheme(theme string) glamour.TermRendererOption {